@@ -13,7 +13,8 @@ var addCmd = &cobra.Command{
 	Long: `Add a new task to your to-do list. Example:
 
   tasker add "Buy groceries"`,
-	Args: cobra.MinimumNArgs(1),
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{autoArchiveAnnotation: ""},
 	Run: func(cmd *cobra.Command, args []string) {
 		description := args[0]
 		err := tasks.AddTask(fileName, description)
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var archiveOlderThan string
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Move completed tasks to the archive file",
+	Long: `Move completed tasks out of the data file into a sibling archive file
+(tasks.archive.csv for tasks.csv), keeping the data file small.
+Archived tasks can be listed with list --archived. Example:
+  tasker archive
+  tasker archive --older-than 7d
+
+Set auto_archive in the config file to archive automatically after add, complete
+and delete:
+  auto_archive: 7d`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		olderThan, err := parseAge(archiveOlderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse --older-than: %v\n", err)
+			return
+		}
+
+		archived, err := tasks.ArchiveTasks(fileName, olderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to archive tasks: %v\n", err)
+			return
+		}
+		fmt.Println("Tasks archived:", archived)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+
+	archiveCmd.Flags().StringVar(&archiveOlderThan, "older-than", "0s", "Only archive tasks completed longer ago than this (e.g. 7d, 12h)")
+}
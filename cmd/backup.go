@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/mergestat/timediff"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manage snapshots of the data file",
+	Long: `A snapshot of the data file is taken before every change that rewrites it. Use
+restore --at to go back to one of them.`,
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots of the data file",
+	Long: `List the available snapshots, oldest first. Example:
+  tasker backup list`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		backups, err := tasks.ListBackups(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list backups: %v\n", err)
+			return
+		}
+		if len(backups) == 0 {
+			fmt.Println("No backups")
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+		fmt.Fprintln(tw, "Time\tAge\tSize\tPath")
+		for _, backup := range backups {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", backup.Time.Local().Format(time.RFC3339), timediff.TimeDiff(backup.Time), backup.Size, backup.Path)
+		}
+		tw.Flush()
+	},
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Take a snapshot of the data file now",
+	Long: `Take a snapshot of the data file, regardless of the automatic backups. Example:
+  tasker backup create`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		backup, err := tasks.CreateBackup(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create backup: %v\n", err)
+			return
+		}
+		fmt.Println("Backup created:", backup.Path)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupCreateCmd)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var compactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Fold an event log into a single snapshot",
+	Long: `A data file ending in .jsonl is an append-only event log: every change is appended
+as an event and the tasks are derived by replaying it. compact replaces the log with a
+single snapshot of the current tasks; the previous log is kept as a backup, even with
+backups.keep set to 0. Example:
+  tasker -f tasks.jsonl compact`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		folded, err := tasks.Compact(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to compact event log: %v\n", err)
+			return
+		}
+		fmt.Println("Events folded into a snapshot:", folded)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compactCmd)
+}
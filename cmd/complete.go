@@ -2,25 +2,29 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/CreepySunny/tasker/tasks"
 	"github.com/spf13/cobra"
 )
 
 var completeCmd = &cobra.Command{
-	Use:   "complete [task ID]",
-	Short: "Mark a task as completed",
-	Long: `Mark a task as completed. Example:
-	  tasker complete 1`,
-	Args: cobra.MinimumNArgs(1),
+	Use:   "complete [task ID]...",
+	Short: "Mark tasks as completed",
+	Long: `Mark one or more tasks as completed. IDs that are not found are reported,
+the other tasks are still completed. Example:
+	  tasker complete 1
+	  tasker complete 1 2 3`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTaskIDs(openTask),
+	Annotations:       map[string]string{autoArchiveAnnotation: ""},
 	Run: func(cmd *cobra.Command, args []string) {
-		taskID := args[0]
-		err := tasks.CompleteTask(fileName, taskID)
+		err := tasks.CompleteTask(fileName, args...)
 		if err != nil {
 			fmt.Printf("Failed to complete task: %v\n", err)
 			return
 		}
-		fmt.Println("TaskID completd:", taskID)
+		fmt.Println("TaskID completd:", strings.Join(args, ", "))
 	},
 }
 
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the shell completion script",
+	Long: `Generate the script that completes tasker commands, flags and task IDs in your shell.
+Task IDs are suggested along with their descriptions. To load completions:
+
+Bash:
+  source <(tasker completion bash)
+  # or for every new shell, on Linux:
+  tasker completion bash > /etc/bash_completion.d/tasker
+
+Zsh:
+  tasker completion zsh > "${fpath[1]}/_tasker"
+
+Fish:
+  tasker completion fish > ~/.config/fish/completions/tasker.fish
+
+PowerShell:
+  tasker completion powershell | Out-String | Invoke-Expression`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		switch args[0] {
+		case "bash":
+			err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			err = rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			err = rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			err = rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to generate completion script: %v\n", err)
+		}
+	},
+}
+
+// completionClient reads the data file for completions, which must never leave the
+// shell waiting on another process. It returns nil when there is no data file, as
+// completing must not create one.
+func completionClient() *tasks.Client {
+	if _, err := os.Stat(fileName); err != nil {
+		return nil
+	}
+	return tasks.New(fileName, tasks.WithLockTimeout(tasks.NoWait))
+}
+
+// completeTaskIDs suggests the IDs of the tasks keep selects, described by their
+// description, as the first argument of a command.
+func completeTaskIDs(keep func(tasks.Task) bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		client := completionClient()
+		if len(args) > 0 || client == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var completions []cobra.Completion
+		for task, err := range client.All(context.Background()) {
+			if err != nil {
+				cobra.CompDebugln(err.Error(), true)
+				return nil, cobra.ShellCompDirectiveError
+			}
+			id := strconv.Itoa(task.ID)
+			if keep(task) && strings.HasPrefix(id, toComplete) {
+				completions = append(completions, cobra.CompletionWithDesc(id, task.Description))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+}
+
+func openTask(t tasks.Task) bool      { return !t.IsDeleted() && !t.IsCompleted }
+func completedTask(t tasks.Task) bool { return !t.IsDeleted() && t.IsCompleted }
+func deletedTask(t tasks.Task) bool   { return t.IsDeleted() }
+func currentTask(t tasks.Task) bool   { return !t.IsDeleted() }
+
+// completeValues suggests the fixed values of a flag.
+func completeValues(values ...string) cobra.CompletionFunc {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// completeBackupTimes suggests the times of the backups, newest first, for restore --at.
+func completeBackupTimes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	client := completionClient()
+	if client == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	backups, err := client.Backups(context.Background())
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []cobra.Completion
+	for _, backup := range slices.Backward(backups) {
+		at := backup.Time.Local().Format(time.RFC3339)
+		completions = append(completions, cobra.CompletionWithDesc(at, fmt.Sprintf("%d bytes", backup.Size)))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
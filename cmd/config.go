@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// config holds the settings read from the config file. Every field is optional.
+type config struct {
+	// AutoArchive archives tasks completed longer ago than this duration (e.g. "7d")
+	// after commands annotated with autoArchiveAnnotation. Empty disables it.
+	AutoArchive string `yaml:"auto_archive"`
+	// Backups controls the snapshots taken before the data file is rewritten.
+	Backups backupConfig `yaml:"backups"`
+}
+
+// backupConfig overrides the tasks package backup rotation defaults.
+type backupConfig struct {
+	// Keep is how many snapshots to retain; 0 disables automatic backups.
+	Keep *int `yaml:"keep"`
+	// MaxAge deletes snapshots older than this duration (e.g. "30d").
+	MaxAge string `yaml:"max_age"`
+}
+
+var (
+	cfgFile string
+	cfg     config
+)
+
+// initConfig reads the config file given by --config, or $HOME/.tasker.yaml when
+// present. A missing default config file is not an error.
+func initConfig() {
+	path := cfgFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		path = filepath.Join(home, ".tasker.yaml")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && cfgFile == "" {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to parse config %s: %v\n", path, err)
+		os.Exit(1)
+	}
+}
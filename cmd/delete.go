@@ -2,25 +2,30 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/CreepySunny/tasker/tasks"
 	"github.com/spf13/cobra"
 )
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete [task ID]",
-	Short: "Delete a task by its ID",
-	Long: `Delete a task from your to-do list by its ID. Example:
-  tasker delete 1`,
-	Args: cobra.MinimumNArgs(1),
+	Use:   "delete [task ID]...",
+	Short: "Move tasks to the trash by their IDs",
+	Long: `Move one or more tasks from your to-do list to the trash by their IDs.
+They can be brought back with restore until they are purged. IDs that are not
+found are reported, the other tasks are still moved. Example:
+  tasker delete 1
+  tasker delete 1 2 3`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTaskIDs(currentTask),
+	Annotations:       map[string]string{autoArchiveAnnotation: ""},
 	Run: func(cmd *cobra.Command, args []string) {
-		taskID := args[0]
-		err := tasks.DeleteTask(fileName, taskID)
+		err := tasks.DeleteTask(fileName, args...)
 		if err != nil {
 			fmt.Printf("Failed to delete task: %v\n", err)
 			return
 		}
-		fmt.Println("Task deleted:", taskID)
+		fmt.Println("Task moved to trash:", strings.Join(args, ", "))
 	},
 }
 
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the data file for corruption and optionally repair it",
+	Long: `Scan the data file for duplicate IDs, unparsable timestamps or booleans, short rows
+and a wrong header, reporting each with its line number. Columns tasker does not know
+are listed too; they are kept as they are.
+With --fix, bad rows are moved to <file>.rejects and all good tasks are kept. Example:
+  tasker doctor
+  tasker doctor --fix`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var problems []tasks.Problem
+		var err error
+		if doctorFix {
+			problems, err = tasks.Repair(fileName)
+		} else {
+			problems, err = tasks.Diagnose(fileName)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check data file: %v\n", err)
+			return
+		}
+
+		found := 0
+		for _, problem := range problems {
+			fmt.Println(problem)
+			if !problem.Info {
+				found++
+			}
+		}
+		switch {
+		case found == 0:
+			fmt.Println("No problems found in", fileName)
+		case doctorFix:
+			fmt.Printf("Fixed %d problems; rejected rows were moved to %s.rejects\n", found, fileName)
+		default:
+			fmt.Printf("Found %d problems; run tasker doctor --fix to repair them\n", found)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Move bad rows to <file>.rejects and rewrite the data file")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit [task ID] [description]",
+	Short: "Change the description of a task",
+	Long: `Replace the description of a task. Example:
+  tasker edit 1 "Tidy my desk and shelves"`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeTaskIDs(currentTask),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskID := args[0]
+		err := tasks.EditTask(fileName, taskID, args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to edit task: %v\n", err)
+			return
+		}
+		fmt.Println("Task edited:", taskID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+}
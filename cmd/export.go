@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write every task to stdout",
+	Long: `Write every task, including completed tasks and the trash, to stdout as CSV in the
+format of the data file, or as JSON Lines (one JSON object per task). Tasks are
+streamed, so very large files are exported without loading them into memory. Example:
+  tasker export > copy.csv
+  tasker export --format jsonl | jq .description`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+
+		var err error
+		switch exportFormat {
+		case "csv":
+			err = tasks.ExportCSV(fileName, out)
+		case "jsonl":
+			enc := json.NewEncoder(out)
+			for task, taskErr := range tasks.AllTasks(fileName) {
+				if err = taskErr; err == nil {
+					err = enc.Encode(task)
+				}
+				if err != nil {
+					break
+				}
+			}
+		default:
+			err = fmt.Errorf("unknown export format %q (expected csv or jsonl)", exportFormat)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export tasks: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportFormat, "format", "csv", "Export format (csv or jsonl)")
+	exportCmd.RegisterFlagCompletionFunc("format", completeValues("csv", "jsonl"))
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [task ID]",
+	Short: "Show the change history of a task",
+	Long: `Show when a task was created, changed, completed, deleted or restored, and by whom. Example:
+  tasker history 1`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTaskIDs(currentTask),
+	Run: func(cmd *cobra.Command, args []string) {
+		events, err := tasks.TaskHistory(fileName, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read history: %v\n", err)
+			return
+		}
+		printEvents(events, false)
+	},
+}
+
+// printEvents writes one line per event, including the task ID when withID is set.
+func printEvents(events []tasks.Event, withID bool) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+	for _, event := range events {
+		fields := make([]string, 0, len(event.Fields))
+		for _, f := range event.Fields {
+			fields = append(fields, fmt.Sprintf("%s: %q -> %q", f.Field, f.Old, f.New))
+		}
+		line := []string{event.Time.Format(time.RFC3339), event.User, event.Op}
+		if withID {
+			line = append(line, fmt.Sprint(event.TaskID))
+		}
+		line = append(line, strings.Join(fields, ", "))
+		fmt.Fprintln(tw, strings.Join(line, "\t"))
+	}
+	tw.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
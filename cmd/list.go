@@ -1,15 +1,34 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"text/tabwriter"
+	"os/signal"
+	"time"
 
+	"github.com/CreepySunny/tasker/table"
 	"github.com/CreepySunny/tasker/tasks"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
-var showAll bool
+var (
+	showAll      bool
+	showArchived bool
+	watchList    bool
+	listColor    string
+	listColumns  string
+	wrapList     bool
+)
+
+// listBatchSize is how many rows are written at a time. The first batch sets the
+// column widths.
+const listBatchSize = 1000
+
+// watchPollInterval is how often list --watch checks the data file where file
+// system notifications are not available.
+const watchPollInterval = time.Second
 
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -18,26 +37,102 @@ var listCmd = &cobra.Command{
 You can use the --all or -a flag to include completed tasks in the list. 
 For example:
   tasker list --all
-This will show both completed and pending tasks.`,
+This will show both completed and pending tasks.
+Use --archived to list the tasks moved to the archive instead.
+Use --watch to keep the list on screen and redraw it whenever the data file changes,
+e.g. through another shell or tasker serve, until interrupted.
+
+On a terminal, long descriptions are shortened to fit its width, or wrapped with
+--wrap, and completed tasks are dimmed. Use --color=always or never to override
+whether to color, which NO_COLOR also turns off, and --columns to choose what to show:
+  tasker list --columns id,desc,completed`,
 	Run: func(cmd *cobra.Command, args []string) {
-		tasks, err := tasks.ListTasks(fileName, showAll)
+		opts, err := listOptions()
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error: %w\n", err)
+			fmt.Fprintf(os.Stderr, "Failed to list tasks: %v\n", err)
+			return
+		}
+		if !watchList {
+			printList(opts)
 			return
 		}
 
-		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		// The first draw may create the data file, which is not worth a second draw.
+		redrawList(opts)
+		for range tasks.New(fileName).Watch(ctx, watchPollInterval) {
+			redrawList(opts)
+		}
+	},
+}
+
+// listOptions returns the table options chosen by the flags of list for stdout.
+func listOptions() (table.Options, error) {
+	columns, err := table.ParseColumns(listColumns)
+	if err != nil {
+		return table.Options{}, err
+	}
+	tty := term.IsTerminal(int(os.Stdout.Fd()))
+	color, err := table.ColorEnabled(listColor, tty)
+	if err != nil {
+		return table.Options{}, err
+	}
+	opts := table.Options{Columns: columns, Color: color, Wrap: wrapList}
+	if tty {
+		if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			opts.Width = width
+		}
+	}
+	return opts, nil
+}
+
+// printList writes the tasks selected by the flags of list as a table.
+func printList(opts table.Options) {
+	list := tasks.ListTasksSeq(fileName, showAll)
+	if showArchived {
+		list = tasks.ArchivedTasksSeq(fileName)
+	}
 
-		for _, task := range tasks {
-			fmt.Fprintln(tw, task.String())
+	tw := table.NewWriter(os.Stdout, opts)
+
+	n := 0
+	for task, err := range list {
+		if err != nil {
+			tw.Flush()
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		tw.Add(task)
+		// Bound memory on very large files; the widths of the first batch are kept
+		if n++; n%listBatchSize == 0 {
+			tw.Flush()
 		}
+	}
 
-		tw.Flush()
-	},
+	tw.Flush()
+}
+
+// redrawList replaces the list on a terminal, or appends it to other output.
+func redrawList(opts table.Options) {
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		// Move the cursor home and clear the screen
+		fmt.Print("\033[H\033[2J")
+	} else {
+		fmt.Println()
+	}
+	fmt.Printf("%s, updated %s\n", fileName, time.Now().Format(time.TimeOnly))
+	printList(opts)
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().BoolVarP(&showAll, "all", "a", false, "Show all tasks")
+	listCmd.Flags().BoolVar(&showArchived, "archived", false, "Show archived tasks")
+	listCmd.Flags().BoolVarP(&watchList, "watch", "w", false, "Redraw the list whenever the data file changes")
+	listCmd.Flags().StringVar(&listColor, "color", "auto", "Color the list: auto, always or never")
+	listCmd.Flags().StringVar(&listColumns, "columns", table.DefaultColumns, "Comma-separated columns to show")
+	listCmd.Flags().BoolVar(&wrapList, "wrap", false, "Wrap long descriptions instead of shortening them")
+	_ = listCmd.RegisterFlagCompletionFunc("color", completeValues("auto", "always", "never"))
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Inspect the lock that coordinates access to the data file",
+}
+
+var lockStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the data file is locked and by which process",
+	Long: `Show whether another tasker process currently holds the data file lock. Example:
+  tasker lock status`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := tasks.LockStatus(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check lock: %v\n", err)
+			return
+		}
+
+		switch {
+		case !info.Locked:
+			fmt.Println("Unlocked:", info.Path)
+		case info.Shared:
+			fmt.Println("Locked for reading by one or more processes:", info.Path)
+		case info.PID == 0:
+			fmt.Println("Locked by an unknown process:", info.Path)
+		default:
+			fmt.Printf("Locked by process %d: %s\n", info.PID, info.Path)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	lockCmd.AddCommand(lockStatusCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var logSince string
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show recent changes to all tasks",
+	Long: `Show the change history of every task, oldest first. Example:
+  tasker log --since 1w`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var since time.Time
+		if logSince != "" {
+			age, err := parseAge(logSince)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to parse --since: %v\n", err)
+				return
+			}
+			since = time.Now().Add(-age)
+		}
+
+		events, err := tasks.History(fileName, since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read history: %v\n", err)
+			return
+		}
+		printEvents(events, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+
+	logCmd.Flags().StringVar(&logSince, "since", "", "Only show changes made within this duration (e.g. 1w, 3d, 12h)")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/mcp"
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol server on stdin and stdout",
+	Long: `Run a Model Context Protocol (MCP) server on stdin and stdout, so AI assistants can
+read and update the tasks. It offers the tools list_tasks, search_tasks, add_task,
+complete_task and delete_task, which take the same file lock as every other command.
+Register it with an assistant as a stdio server, e.g.:
+  {"command": "tasker", "args": ["-f", "/path/to/tasks.csv", "mcp"]}`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := mcp.Serve(context.Background(), tasks.New(fileName), os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to serve MCP: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var purgeOlderThan string
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently remove tasks from the trash",
+	Long: `Permanently remove deleted tasks. By default the whole trash is emptied;
+use --older-than to only purge tasks deleted a while ago. Example:
+  tasker purge
+  tasker purge --older-than 30d`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		olderThan, err := parseAge(purgeOlderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse --older-than: %v\n", err)
+			return
+		}
+
+		purged, err := tasks.PurgeTasks(fileName, olderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to purge trash: %v\n", err)
+			return
+		}
+		fmt.Println("Tasks purged:", purged)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+
+	purgeCmd.Flags().StringVar(&purgeOlderThan, "older-than", "0s", "Only purge tasks deleted longer ago than this (e.g. 30d, 12h)")
+}
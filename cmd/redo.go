@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Redo the last undone change",
+	Long: `Reapply the most recent change reverted by undo. Example:
+  tasker redo`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entry, err := tasks.Redo(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to redo: %v\n", err)
+			return
+		}
+		fmt.Println("Redone:", describeEntry(entry))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(redoCmd)
+}
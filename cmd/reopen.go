@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var reopenCmd = &cobra.Command{
+	Use:   "reopen [task ID]...",
+	Short: "Mark completed tasks as not completed",
+	Long: `Clear the completion of one or more tasks so they show up in list again.
+IDs that are not found are reported, the other tasks are still reopened. Example:
+  tasker reopen 1
+  tasker reopen 1 2 3`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTaskIDs(completedTask),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := tasks.ReopenTask(fileName, args...)
+		if err != nil {
+			fmt.Printf("Failed to reopen task: %v\n", err)
+			return
+		}
+		fmt.Println("Task reopened:", strings.Join(args, ", "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reopenCmd)
+}
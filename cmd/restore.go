@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreAt  string
+	restoreYes bool
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [task ID]...",
+	Short: "Restore deleted tasks from the trash, or the whole file from a backup",
+	Long: `Move tasks out of the trash and back onto your to-do list, or with --at, replace
+every task with the latest backup taken at or before the given time. The changes are
+shown before anything is replaced, and nothing is replaced if the tasks change in the
+meantime. Example:
+  tasker restore 1
+  tasker restore 1 2 3
+  tasker restore --at 2026-10-01T09:00
+  tasker restore --at 2026-10-01 --yes`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if restoreAt != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if restoreAt != "" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeTaskIDs(deletedTask)(cmd, args, toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if restoreAt != "" {
+			restoreBackup()
+			return
+		}
+
+		if err := tasks.RestoreTask(fileName, args...); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to restore task: %v\n", err)
+			return
+		}
+		fmt.Println("Task restored:", strings.Join(args, ", "))
+	},
+}
+
+func restoreBackup() {
+	at, err := parseTime(restoreAt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --at: %v\n", err)
+		return
+	}
+	backup, err := tasks.BackupAt(fileName, at)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find backup: %v\n", err)
+		return
+	}
+	changes, version, err := tasks.DiffBackup(fileName, backup)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to compare backup: %v\n", err)
+		return
+	}
+
+	fmt.Println("Backup from", backup.Time.Local().Format(time.RFC3339))
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+	for _, change := range changes {
+		fmt.Println(describeChange(change))
+	}
+	if !restoreYes && !confirm("Restore this backup?") {
+		fmt.Println("Restore cancelled")
+		return
+	}
+
+	err = tasks.RestoreBackup(fileName, backup, version)
+	if errors.Is(err, tasks.ErrConflict) {
+		fmt.Fprintln(os.Stderr, "Failed to restore backup: the tasks were changed since the diff was shown; run restore again to review the new changes")
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to restore backup: %v\n", err)
+		return
+	}
+	fmt.Println("Backup restored")
+}
+
+// describeChange prints a change as a diff line: + for added, - for removed
+// and ~ with the changed fields for modified tasks.
+func describeChange(change tasks.Change) string {
+	switch {
+	case change.Before == nil:
+		return fmt.Sprintf("+ %d %q", change.ID, change.After.Description)
+	case change.After == nil:
+		return fmt.Sprintf("- %d %q", change.ID, change.Before.Description)
+	}
+	fields := make([]string, 0, len(change.Fields()))
+	for _, field := range change.Fields() {
+		fields = append(fields, fmt.Sprintf("%s: %q -> %q", field.Field, field.Old, field.New))
+	}
+	return fmt.Sprintf("~ %d %q (%s)", change.ID, change.Before.Description, strings.Join(fields, ", "))
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no.
+func confirm(question string) bool {
+	fmt.Print(question, " [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVar(&restoreAt, "at", "", "Restore every task from the latest backup taken at or before this time")
+	restoreCmd.Flags().BoolVarP(&restoreYes, "yes", "y", false, "Restore a backup without asking for confirmation")
+	restoreCmd.RegisterFlagCompletionFunc("at", completeBackupTimes)
+}
@@ -4,12 +4,23 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"time"
 
+	"github.com/CreepySunny/tasker/tasks"
 	"github.com/spf13/cobra"
 )
 
-var fileName string
+var (
+	fileName    string
+	lockTimeout time.Duration
+	noWait      bool
+)
+
+// autoArchiveAnnotation marks commands after which auto_archive from the config is applied.
+// Only commands that already change the data file carry it, so reading never writes.
+const autoArchiveAnnotation = "tasker/auto-archive"
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -30,6 +41,11 @@ Examples:
 - View all tasks: tasker list
 - Mark a task as completed: tasker complete 1`,
 	TraverseChildren: true,
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if _, ok := cmd.Annotations[autoArchiveAnnotation]; ok {
+			autoArchive()
+		}
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -49,10 +65,51 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.tasker.yaml)")
+	cobra.OnInitialize(initConfig, initLocking, initBackups)
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.tasker.yaml)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.PersistentFlags().StringVarP(&fileName, "file", "f", "tasks.csv", "File to store tasks")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 0, "Give up if the data file stays locked by another process this long (0 waits forever)")
+	rootCmd.PersistentFlags().BoolVar(&noWait, "no-wait", false, "Fail immediately if the data file is locked by another process")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// autoArchive archives old completed tasks when auto_archive is set in the config.
+func autoArchive() {
+	if cfg.AutoArchive == "" {
+		return
+	}
+	olderThan, err := parseAge(cfg.AutoArchive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid auto_archive in config: %v\n", err)
+		return
+	}
+	if _, err := tasks.AutoArchiveTasks(fileName, olderThan); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to archive tasks: %v\n", err)
+	}
+}
+
+// initLocking applies --lock-timeout and --no-wait to the tasks package.
+func initLocking() {
+	tasks.LockTimeout = lockTimeout
+	if noWait {
+		tasks.LockTimeout = tasks.NoWait
+	}
+}
+
+// initBackups applies the backups section of the config to the tasks package.
+func initBackups() {
+	if cfg.Backups.Keep != nil {
+		tasks.BackupKeep = *cfg.Backups.Keep
+	}
+	if cfg.Backups.MaxAge != "" {
+		maxAge, err := parseAge(cfg.Backups.MaxAge)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid backups.max_age in config: %v\n", err)
+			os.Exit(1)
+		}
+		tasks.BackupMaxAge = maxAge
+	}
+}
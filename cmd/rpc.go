@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/rpc"
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Answer JSON-RPC 2.0 requests on stdin and stdout",
+	Long: `Answer JSON-RPC 2.0 requests read from stdin, one per line, with one response per
+line on stdout, until stdin is closed. Editor plugins can keep a single tasker process
+running instead of starting one per action. The methods are list, search, get, add,
+edit, complete, reopen and delete; a "changed" notification is sent whenever the data
+file changes on disk. Example:
+  echo '{"jsonrpc":"2.0","id":1,"method":"add","params":{"description":"Tidy my desk"}}' | tasker rpc`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := rpc.Serve(context.Background(), tasks.New(fileName), os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to serve JSON-RPC: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rpcCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/CreepySunny/tasker/server"
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/spf13/cobra"
+)
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the tasks over a local HTTP REST API and web UI",
+	Long: `Serve the tasks of the data file as a JSON REST API until interrupted. Requests take
+the same file lock as every other command, so tasker can keep being used alongside.
+The OpenAPI document describing the API is served at /openapi.json, and a web UI for
+viewing and changing the tasks, which updates itself when the data file changes, at /.
+Example:
+  tasker serve --addr 127.0.0.1:8080
+  curl -H 'Content-Type: application/json' -d '{"description":"Tidy my desk"}' http://127.0.0.1:8080/tasks
+
+Requests must be addressed to the listen address, and changes must be sent as
+application/json, so web pages of other sites cannot use the API.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ln, err := net.Listen("tcp", serveAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to serve tasks: %v\n", err)
+			return
+		}
+		srv := &http.Server{
+			Handler:           server.Handler(tasks.New(fileName), ln.Addr().String()),
+			ReadHeaderTimeout: 10 * time.Second,
+			// Ends the event streams of the web UI on shutdown, which would otherwise stay open.
+			BaseContext: func(net.Listener) context.Context { return ctx },
+		}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("Serving %s on http://%s\n", fileName, ln.Addr())
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Failed to serve tasks: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+}
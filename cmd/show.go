@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/mergestat/timediff"
+	"github.com/spf13/cobra"
+)
+
+var showOutput string
+
+var showCmd = &cobra.Command{
+	Use:   "show [task ID]",
+	Short: "Show the details of a single task",
+	Long: `Show every field of a single task, given by ID or UUID. Example:
+  tasker show 1
+  tasker show 0b0e7a4e-5c1f-4f0e-9a57-3f4be3a1c2d9
+  tasker show 1 --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTaskIDs(currentTask),
+	Run: func(cmd *cobra.Command, args []string) {
+		task, err := tasks.GetTask(fileName, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to show task: %v\n", err)
+			return
+		}
+
+		switch showOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(task); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to encode task: %v\n", err)
+			}
+		case "text":
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+			fmt.Fprintf(tw, "ID:\t%d\n", task.ID)
+			fmt.Fprintf(tw, "UUID:\t%s\n", task.UUID)
+			fmt.Fprintf(tw, "Description:\t%s\n", task.Description)
+			fmt.Fprintf(tw, "Created:\t%s (%s)\n", task.CreatedAt.Format(time.RFC3339), timediff.TimeDiff(task.CreatedAt))
+			if task.IsCompleted && !task.CompletedAt.IsZero() {
+				fmt.Fprintf(tw, "Completed:\t%s (%s)\n", task.CompletedAt.Format(time.RFC3339), timediff.TimeDiff(task.CompletedAt))
+			} else {
+				fmt.Fprintf(tw, "Completed:\t%t\n", task.IsCompleted)
+			}
+			if task.IsDeleted() {
+				fmt.Fprintf(tw, "Deleted:\t%s (%s)\n", task.DeletedAt.Format(time.RFC3339), timediff.TimeDiff(task.DeletedAt))
+			}
+			tw.Flush()
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown output format %q (expected text or json)\n", showOutput)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+
+	showCmd.Flags().StringVarP(&showOutput, "output", "o", "text", "Output format (text or json)")
+	showCmd.RegisterFlagCompletionFunc("output", completeValues("text", "json"))
+}
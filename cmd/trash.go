@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/mergestat/timediff"
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List deleted tasks",
+	Long: `List the tasks in the trash along with when they were deleted.
+Use restore to bring one back or purge to remove them for good. Example:
+  tasker trash`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		trashed, err := tasks.ListTrash(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list trash: %v\n", err)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+		for _, task := range trashed {
+			fmt.Fprintf(tw, "%d\t%s\tdeleted %s\n", task.ID, task.Description, timediff.TimeDiff(task.DeletedAt))
+		}
+		tw.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+}
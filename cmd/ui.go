@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/CreepySunny/tasker/tui"
+	"github.com/spf13/cobra"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Triage tasks in a full-screen terminal interface",
+	Long: `Open a full-screen terminal interface listing the tasks, with a sidebar to switch
+between open, all, completed, trashed and archived tasks. Move with the arrow keys
+or j/k, add with a, edit with e, complete or reopen with space, delete with d, undo
+with u and filter with /. Press ? for every key binding. Changes made elsewhere
+show up while it is open. Example:
+  tasker ui`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := tui.Run(tasks.New(fileName)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to run terminal interface: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
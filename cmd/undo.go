@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/mergestat/timediff"
+	"github.com/spf13/cobra"
+)
+
+var undoList bool
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last change to your tasks",
+	Long: `Revert the most recent add, complete or delete. Run it repeatedly to step further back,
+or pass --list to see what would be reverted. Example:
+  tasker undo
+  tasker undo --list`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if undoList {
+			entries, err := tasks.UndoList(fileName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to read undo history: %v\n", err)
+				return
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+			for _, entry := range entries {
+				fmt.Fprintf(tw, "%s\t%s\n", timediff.TimeDiff(entry.Time), describeEntry(entry))
+			}
+			tw.Flush()
+			return
+		}
+
+		entry, err := tasks.Undo(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to undo: %v\n", err)
+			return
+		}
+		fmt.Println("Undone:", describeEntry(entry))
+	},
+}
+
+// describeEntry summarizes a journal entry, e.g. `delete 12 "Buy milk"`.
+func describeEntry(entry tasks.JournalEntry) string {
+	parts := make([]string, 0, len(entry.Changes))
+	for _, change := range entry.Changes {
+		task := change.After
+		if task == nil {
+			task = change.Before
+		}
+		parts = append(parts, fmt.Sprintf("%d %q", change.ID, task.Description))
+	}
+	return entry.Op + " " + strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().BoolVarP(&undoList, "list", "l", false, "List the changes that would be undone")
+}
@@ -0,0 +1,202 @@
+// Package mcp serves the tasks of a data file to AI assistants as the tools of a
+// Model Context Protocol server, speaking JSON-RPC 2.0 over a pair of streams such as
+// stdin and stdout.
+//
+// The tools are list_tasks, search_tasks, add_task, complete_task and delete_task.
+// Their input and output schemas are derived from the Go types they decode and
+// return, so they follow tasks.Task as it changes.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"slices"
+
+	"github.com/CreepySunny/tasker/rpc"
+	"github.com/CreepySunny/tasker/tasks"
+)
+
+// protocolVersions are the MCP revisions the server speaks, newest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// tool is an MCP tool: its description for listing, and how to call it.
+type tool struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	InputSchema  map[string]any `json:"inputSchema"`
+	OutputSchema map[string]any `json:"outputSchema"`
+	call         func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// newTool derives the schemas of a tool from the arguments P it takes and the
+// result R it returns. R must encode as a JSON object.
+func newTool[P, R any](name, description string, fn func(ctx context.Context, args P) (R, error)) tool {
+	return tool{
+		Name:         name,
+		Description:  description,
+		InputSchema:  schemaFor(reflect.TypeFor[P]()),
+		OutputSchema: schemaFor(reflect.TypeFor[R]()),
+		call: func(ctx context.Context, raw json.RawMessage) (any, error) {
+			var args P
+			if err := rpc.DecodeParams(raw, &args); err != nil {
+				return nil, err
+			}
+			return fn(ctx, args)
+		},
+	}
+}
+
+type listArgs struct {
+	All bool `json:"all,omitempty" desc:"Include completed tasks"`
+}
+
+type searchArgs struct {
+	Query string `json:"query" desc:"Words that must all appear in the description, ignoring case"`
+	All   bool   `json:"all,omitempty" desc:"Include completed tasks"`
+}
+
+type addArgs struct {
+	Description string `json:"description" desc:"What needs to be done"`
+}
+
+type idArgs struct {
+	ID int `json:"id" desc:"The short ID of the task"`
+}
+
+type taskList struct {
+	Tasks []tasks.Task `json:"tasks"`
+}
+
+type taskResult struct {
+	Task tasks.Task `json:"task"`
+}
+
+func newTaskList(list []tasks.Task, err error) (taskList, error) {
+	if list == nil {
+		list = []tasks.Task{}
+	}
+	return taskList{Tasks: list}, err
+}
+
+// toolsFor returns the tools working on the tasks of client.
+func toolsFor(client *tasks.Client) []tool {
+	yes := true
+	return []tool{
+		newTool("list_tasks", "List the open tasks, or every task that is not in the trash.",
+			func(ctx context.Context, args listArgs) (taskList, error) {
+				return newTaskList(client.List(ctx, args.All))
+			}),
+		newTool("search_tasks", "Find tasks whose description contains every word of a query.",
+			func(ctx context.Context, args searchArgs) (taskList, error) {
+				return newTaskList(client.Search(ctx, args.Query, args.All))
+			}),
+		newTool("add_task", "Add a task and return it with its ID.",
+			func(ctx context.Context, args addArgs) (taskResult, error) {
+				if args.Description == "" {
+					return taskResult{}, errors.New("description must not be empty")
+				}
+				task, err := client.Add(ctx, args.Description)
+				return taskResult{task}, err
+			}),
+		newTool("complete_task", "Mark a task as completed.",
+			func(ctx context.Context, args idArgs) (taskResult, error) {
+				task, err := client.Edit(ctx, args.ID, tasks.TaskPatch{IsCompleted: &yes}, "")
+				return taskResult{task}, err
+			}),
+		newTool("delete_task", "Move a task to the trash, from where it can still be restored.",
+			func(ctx context.Context, args idArgs) (taskResult, error) {
+				task, err := client.Edit(ctx, args.ID, tasks.TaskPatch{IsDeleted: &yes}, "")
+				return taskResult{task}, err
+			}),
+	}
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content           []content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError,omitempty"`
+}
+
+// Serve answers MCP requests read from r on w until r ends or ctx is done. Only a
+// failure to read r or write w is returned.
+func Serve(ctx context.Context, client *tasks.Client, r io.Reader, w io.Writer) error {
+	return rpc.NewConn(r, w).Serve(ctx, methods(toolsFor(client)))
+}
+
+func methods(tools []tool) map[string]rpc.Handler {
+	return map[string]rpc.Handler{
+		"initialize": func(ctx context.Context, raw json.RawMessage) (any, error) {
+			var params struct {
+				ProtocolVersion string `json:"protocolVersion"`
+			}
+			// Clients send more than the version; none of it changes the answer.
+			json.Unmarshal(raw, &params)
+			version := protocolVersions[0]
+			if slices.Contains(protocolVersions, params.ProtocolVersion) {
+				version = params.ProtocolVersion
+			}
+			return map[string]any{
+				"protocolVersion": version,
+				"capabilities":    map[string]any{"tools": map[string]any{}},
+				"serverInfo":      map[string]any{"name": "tasker", "version": serverVersion()},
+			}, nil
+		},
+		"notifications/initialized": func(ctx context.Context, raw json.RawMessage) (any, error) {
+			return nil, nil
+		},
+		"ping": func(ctx context.Context, raw json.RawMessage) (any, error) {
+			return struct{}{}, nil
+		},
+		"tools/list": func(ctx context.Context, raw json.RawMessage) (any, error) {
+			return map[string]any{"tools": tools}, nil
+		},
+		"tools/call": func(ctx context.Context, raw json.RawMessage) (any, error) {
+			var params struct {
+				Name      string          `json:"name"`
+				Arguments json.RawMessage `json:"arguments"`
+			}
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, &rpc.Error{Code: rpc.CodeInvalidParams, Message: err.Error()}
+			}
+			i := slices.IndexFunc(tools, func(t tool) bool { return t.Name == params.Name })
+			if i < 0 {
+				return nil, &rpc.Error{Code: rpc.CodeInvalidParams, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+			}
+			res, err := tools[i].call(ctx, params.Arguments)
+			var rpcErr *rpc.Error
+			if errors.As(err, &rpcErr) {
+				return nil, err
+			}
+			// Failures of the tool itself are results, so the assistant sees them.
+			if err != nil {
+				return callResult{Content: []content{{"text", err.Error()}}, IsError: true}, nil
+			}
+			text, err := json.Marshal(res)
+			if err != nil {
+				return nil, err
+			}
+			return callResult{
+				Content:           []content{{"text", string(text)}},
+				StructuredContent: res,
+			}, nil
+		},
+	}
+}
+
+// serverVersion is the version of the tasker module in the running binary.
+func serverVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
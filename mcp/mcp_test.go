@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/CreepySunny/tasker/tasks"
+)
+
+type message struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+// exchange sends requests to a server for filename and returns its responses.
+func exchange(t *testing.T, filename string, requests ...string) []message {
+	t.Helper()
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(requests, "\n") + "\n")
+	if err := Serve(context.Background(), tasks.New(filename), in, &out); err != nil {
+		t.Fatalf("Serve error: %v", err)
+	}
+	var messages []message
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var msg message
+		if err := dec.Decode(&msg); err != nil {
+			t.Fatalf("invalid response in %q: %v", out.String(), err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func call(id int, tool, args string) string {
+	return `{"jsonrpc":"2.0","id":` + strconv.Itoa(id) + `,"method":"tools/call","params":{"name":"` + tool + `","arguments":` + args + `}}`
+}
+
+func TestInitialize(t *testing.T) {
+	responses := exchange(t, filepath.Join(t.TempDir(), "tasks.csv"),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	)
+	if len(responses) != 3 {
+		t.Fatalf("expected no response to the notification, got %d responses", len(responses))
+	}
+	var init struct {
+		ProtocolVersion string         `json:"protocolVersion"`
+		Capabilities    map[string]any `json:"capabilities"`
+		ServerInfo      struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	json.Unmarshal(responses[0].Result, &init)
+	if init.ProtocolVersion != "2025-03-26" || init.Capabilities["tools"] == nil || init.ServerInfo.Name != "tasker" {
+		t.Errorf("initialize = %s", responses[0].Result)
+	}
+	json.Unmarshal(responses[1].Result, &init)
+	if init.ProtocolVersion != protocolVersions[0] {
+		t.Errorf("expected the latest version for an unknown one, got %s", init.ProtocolVersion)
+	}
+	if string(responses[2].Result) != "{}" {
+		t.Errorf("ping = %s, want {}", responses[2].Result)
+	}
+}
+
+func TestToolsList(t *testing.T) {
+	responses := exchange(t, filepath.Join(t.TempDir(), "tasks.csv"), `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	var list struct {
+		Tools []struct {
+			Name         string         `json:"name"`
+			InputSchema  map[string]any `json:"inputSchema"`
+			OutputSchema map[string]any `json:"outputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(responses[0].Result, &list); err != nil {
+		t.Fatalf("tools/list = %s: %v", responses[0].Result, err)
+	}
+	names := map[string]bool{}
+	for _, tool := range list.Tools {
+		names[tool.Name] = true
+		if tool.InputSchema["type"] != "object" || tool.OutputSchema["type"] != "object" {
+			t.Errorf("%s: schemas must describe objects, got %v and %v", tool.Name, tool.InputSchema, tool.OutputSchema)
+		}
+	}
+	for _, name := range []string{"list_tasks", "add_task", "complete_task", "delete_task", "search_tasks"} {
+		if !names[name] {
+			t.Errorf("expected tool %s, got %v", name, names)
+		}
+	}
+}
+
+func TestToolSchemaFollowsTask(t *testing.T) {
+	schema := toolsFor(tasks.New("tasks.csv"))[0].OutputSchema
+	taskSchema := schema["properties"].(map[string]any)["tasks"].(map[string]any)["items"].(map[string]any)
+	props := taskSchema["properties"].(map[string]any)
+	if props["id"].(map[string]any)["type"] != "integer" || props["created_at"].(map[string]any)["format"] != "date-time" {
+		t.Errorf("task schema = %v", taskSchema)
+	}
+	required := taskSchema["required"].([]string)
+	if !strings.Contains(strings.Join(required, ","), "description") || strings.Contains(strings.Join(required, ","), "deleted_at") {
+		t.Errorf("required = %v, want description but not deleted_at", required)
+	}
+}
+
+func TestToolsCall(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "tasks.csv")
+	responses := exchange(t, filename,
+		call(1, "add_task", `{"description":"Buy groceries"}`),
+		call(2, "add_task", `{"description":"Water the plants"}`),
+		call(3, "complete_task", `{"id":1}`),
+		call(4, "delete_task", `{"id":2}`),
+		call(5, "search_tasks", `{"query":"groceries","all":true}`),
+		call(6, "list_tasks", `{}`),
+		call(7, "complete_task", `{"id":99}`),
+		call(8, "add_task", `{"title":"x"}`),
+		call(9, "rename_task", `{}`),
+	)
+	if len(responses) != 9 {
+		t.Fatalf("expected 9 responses, got %d", len(responses))
+	}
+
+	var result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		StructuredContent struct {
+			Task  tasks.Task   `json:"task"`
+			Tasks []tasks.Task `json:"tasks"`
+		} `json:"structuredContent"`
+		IsError bool `json:"isError"`
+	}
+	decode := func(i int) {
+		t.Helper()
+		result.StructuredContent.Tasks = nil
+		if err := json.Unmarshal(responses[i].Result, &result); err != nil {
+			t.Fatalf("response %d = %s: %v", i+1, responses[i].Result, err)
+		}
+	}
+
+	decode(0)
+	if result.IsError || result.StructuredContent.Task.ID != 1 || !strings.Contains(result.Content[0].Text, "Buy groceries") {
+		t.Errorf("add_task = %s", responses[0].Result)
+	}
+	decode(2)
+	if !result.StructuredContent.Task.IsCompleted {
+		t.Errorf("complete_task = %s", responses[2].Result)
+	}
+	decode(3)
+	if !result.StructuredContent.Task.IsDeleted() {
+		t.Errorf("delete_task = %s", responses[3].Result)
+	}
+	decode(4)
+	if len(result.StructuredContent.Tasks) != 1 || result.StructuredContent.Tasks[0].ID != 1 {
+		t.Errorf("search_tasks = %s", responses[4].Result)
+	}
+	decode(5)
+	if result.StructuredContent.Tasks == nil || len(result.StructuredContent.Tasks) != 0 {
+		t.Errorf("list_tasks = %s, want an empty list", responses[5].Result)
+	}
+	decode(6)
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "not found") {
+		t.Errorf("complete_task of an unknown task = %s, want a tool error", responses[6].Result)
+	}
+	for _, i := range []int{7, 8} {
+		if responses[i].Error == nil || responses[i].Error.Code != -32602 {
+			t.Errorf("response %d = %+v, want an invalid params error", i+1, responses[i])
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Error codes defined by JSON-RPC 2.0.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is sent as the error response to a request. Handlers return it to choose
+// the code; any other error is sent with CodeInternalError.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Handler answers a request for one method with its result.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	// ID is left nil for notifications, which get no response.
+	ID json.RawMessage `json:"id"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// Conn exchanges JSON-RPC 2.0 messages over a pair of streams. Messages are
+// single-line JSON values separated by newlines, in both directions.
+type Conn struct {
+	in  *bufio.Reader
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewConn returns a Conn reading requests from r and writing to w.
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{in: bufio.NewReader(r), enc: json.NewEncoder(w)}
+}
+
+// Notify sends a notification. It may be called while Serve is running.
+func (c *Conn) Notify(method string, params any) error {
+	return c.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (c *Conn) write(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+	return nil
+}
+
+// Serve answers requests, and batches of requests, with the handler for their
+// method one at a time until the input ends or ctx is done. Only a failure to read
+// or write is returned; failed requests are answered with an error response.
+func (c *Conn) Serve(ctx context.Context, methods map[string]Handler) error {
+	for ctx.Err() == nil {
+		line, err := c.in.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if out := c.handleMessage(ctx, methods, line); out != nil {
+				if werr := c.write(out); werr != nil {
+					return werr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+	return nil
+}
+
+// handleMessage answers a request or a batch of requests, returning nil when no
+// response is due because the message held only notifications.
+func (c *Conn) handleMessage(ctx context.Context, methods map[string]Handler, msg []byte) any {
+	if msg[0] != '[' {
+		if resp := handle(ctx, methods, msg); resp != nil {
+			return resp
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(msg, &batch); err != nil {
+		return errorResponse(nil, &Error{CodeParseError, err.Error()})
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, &Error{CodeInvalidRequest, "empty batch"})
+	}
+	var responses []*response
+	for _, msg := range batch {
+		if resp := handle(ctx, methods, msg); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// handle answers a single request, or returns nil for a notification.
+func handle(ctx context.Context, methods map[string]Handler, msg []byte) *response {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return errorResponse(nil, &Error{CodeParseError, err.Error()})
+		}
+		return errorResponse(nil, &Error{CodeInvalidRequest, err.Error()})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, &Error{CodeInvalidRequest, `expected "jsonrpc": "2.0" and a method`})
+	}
+
+	var res any
+	var err error
+	if h, ok := methods[req.Method]; ok {
+		res, err = h(ctx, req.Params)
+	} else {
+		err = &Error{CodeMethodNotFound, fmt.Sprintf("method %q not found", req.Method)}
+	}
+	if req.ID == nil {
+		return nil
+	}
+	var data []byte
+	if err == nil {
+		data, err = json.Marshal(res)
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{CodeInternalError, err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+	return &response{JSONRPC: "2.0", Result: data, ID: req.ID}
+}
+
+func errorResponse(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", Error: err, ID: id}
+}
+
+// DecodeParams reads the named parameters of a request into v, rejecting unknown
+// ones. Missing parameters leave v unchanged.
+func DecodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &Error{CodeInvalidParams, fmt.Sprintf("invalid params: %v", err)}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+// Package rpc answers JSON-RPC 2.0 requests about the tasks of a data file over a
+// pair of streams, such as the stdin and stdout of an editor plugin's child process.
+//
+// Messages are single-line JSON values separated by newlines, in both directions.
+// Parameters are passed by name. The methods are:
+//
+//	list     {"all": bool}                          open tasks, or all tasks with all
+//	search   {"query": string, "all": bool}         tasks whose description has every word of query
+//	get      {"id": int}                            a task, including ones in the trash or archive
+//	add      {"description": string}                the new task
+//	edit     {"id": int, "description": string,
+//	          "is_completed": bool, "is_deleted": bool,
+//	          "version": string}                    the changed task
+//	complete {"id": int, "version": string}         the completed task
+//	reopen   {"id": int, "version": string}         the reopened task
+//	delete   {"id": int, "version": string}         the task, now in the trash
+//
+// Tasks are returned as in the data file, with an added "version". Passing it back
+// as "version" makes a change fail with CodeConflict if the task has changed since.
+// Whenever the data file changes on disk, the server sends a "changed" notification.
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+)
+
+// Error codes for failures of the tasks package.
+const (
+	CodeTaskNotFound = -32001
+	CodeConflict     = -32002
+	CodeLocked       = -32003
+)
+
+// ChangedMethod is the notification sent when the data file changes on disk.
+const ChangedMethod = "changed"
+
+// pollInterval is how often the data file is checked for changes.
+var pollInterval = 500 * time.Millisecond
+
+// taskResult is a task as returned to clients, with its version for later changes.
+type taskResult struct {
+	tasks.Task
+	Version string `json:"version"`
+}
+
+func result(task tasks.Task) taskResult {
+	return taskResult{Task: task, Version: task.Version()}
+}
+
+func results(list []tasks.Task) []taskResult {
+	out := make([]taskResult, 0, len(list))
+	for _, task := range list {
+		out = append(out, result(task))
+	}
+	return out
+}
+
+// Serve answers the requests read from r on w until r ends or ctx is done, and
+// notifies w of changes to the data file in between. Only a failure to read r or
+// write w is returned; failed requests are answered with an error response.
+func Serve(ctx context.Context, client *tasks.Client, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	conn := NewConn(r, w)
+
+	var wg sync.WaitGroup
+	changes := client.Watch(ctx, pollInterval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range changes {
+			conn.Notify(ChangedMethod, nil)
+		}
+	}()
+	// No notification may be written once Serve has returned.
+	defer wg.Wait()
+	defer cancel()
+
+	return conn.Serve(ctx, methods(client))
+}
+
+// methods returns the handlers for the methods listed in the package documentation.
+func methods(client *tasks.Client) map[string]Handler {
+	return map[string]Handler{
+		"list": taskHandler(func(ctx context.Context, params struct {
+			All bool `json:"all"`
+		}) (any, error) {
+			list, err := client.List(ctx, params.All)
+			return results(list), err
+		}),
+		"search": taskHandler(func(ctx context.Context, params struct {
+			Query string `json:"query"`
+			All   bool   `json:"all"`
+		}) (any, error) {
+			list, err := client.Search(ctx, params.Query, params.All)
+			return results(list), err
+		}),
+		"get": taskHandler(func(ctx context.Context, params idParams) (any, error) {
+			if err := params.check(); err != nil {
+				return nil, err
+			}
+			task, err := client.Get(ctx, params.ID)
+			return result(task), err
+		}),
+		"add": taskHandler(func(ctx context.Context, params struct {
+			Description string `json:"description"`
+		}) (any, error) {
+			if params.Description == "" {
+				return nil, &Error{CodeInvalidParams, "invalid params: description is required"}
+			}
+			task, err := client.Add(ctx, params.Description)
+			return result(task), err
+		}),
+		"edit": taskHandler(func(ctx context.Context, params struct {
+			idParams
+			tasks.TaskPatch
+		}) (any, error) {
+			return edit(ctx, client, params.idParams, params.TaskPatch)
+		}),
+		"complete": patchHandler(client, tasks.TaskPatch{IsCompleted: ptr(true)}),
+		"reopen":   patchHandler(client, tasks.TaskPatch{IsCompleted: ptr(false)}),
+		"delete":   patchHandler(client, tasks.TaskPatch{IsDeleted: ptr(true)}),
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// taskHandler decodes the params of a request into P for fn and gives failures of
+// the tasks package their error code.
+func taskHandler[P any](fn func(ctx context.Context, params P) (any, error)) Handler {
+	return func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params P
+		if err := DecodeParams(raw, &params); err != nil {
+			return nil, err
+		}
+		res, err := fn(ctx, params)
+		if err != nil {
+			return nil, taskError(err)
+		}
+		return res, nil
+	}
+}
+
+// taskError gives failures of the tasks package their error code.
+func taskError(err error) error {
+	var locked *tasks.ErrLocked
+	switch {
+	case errors.Is(err, tasks.ErrTaskNotFound):
+		return &Error{CodeTaskNotFound, err.Error()}
+	case errors.Is(err, tasks.ErrConflict):
+		return &Error{CodeConflict, err.Error()}
+	case errors.As(err, &locked):
+		return &Error{CodeLocked, err.Error()}
+	}
+	return err
+}
+
+type idParams struct {
+	ID      int    `json:"id"`
+	Version string `json:"version"`
+}
+
+func (p idParams) check() error {
+	if p.ID <= 0 {
+		return &Error{CodeInvalidParams, "invalid params: id is required"}
+	}
+	return nil
+}
+
+// patchHandler applies patch to the task named in the params of a request.
+func patchHandler(client *tasks.Client, patch tasks.TaskPatch) Handler {
+	return taskHandler(func(ctx context.Context, params idParams) (any, error) {
+		return edit(ctx, client, params, patch)
+	})
+}
+
+func edit(ctx context.Context, client *tasks.Client, params idParams, patch tasks.TaskPatch) (any, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	task, err := client.Edit(ctx, params.ID, patch, params.Version)
+	if err != nil {
+		return nil, err
+	}
+	return result(task), nil
+}
@@ -0,0 +1,189 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+)
+
+// session runs Serve on a new data file and exchanges messages with it one line at a time.
+type session struct {
+	t        *testing.T
+	filename string
+	in       *io.PipeWriter
+	out      *bufio.Scanner
+}
+
+func newSession(t *testing.T) *session {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "tasks.csv")
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(context.Background(), tasks.New(filename), inR, outW)
+		outW.Close()
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		go io.Copy(io.Discard, outR)
+		if err := <-done; err != nil {
+			t.Errorf("Serve error: %v", err)
+		}
+	})
+	return &session{t: t, filename: filename, in: inW, out: bufio.NewScanner(outR)}
+}
+
+func (s *session) send(msg string) {
+	s.t.Helper()
+	if _, err := io.WriteString(s.in, msg+"\n"); err != nil {
+		s.t.Fatalf("failed to send %s: %v", msg, err)
+	}
+}
+
+// receive returns the next message, skipping change notifications unless they are wanted.
+func (s *session) receive(notifications bool) map[string]any {
+	s.t.Helper()
+	for s.out.Scan() {
+		var msg map[string]any
+		if err := json.Unmarshal(s.out.Bytes(), &msg); err != nil {
+			s.t.Fatalf("invalid message %q: %v", s.out.Text(), err)
+		}
+		if msg["method"] == ChangedMethod && !notifications {
+			continue
+		}
+		return msg
+	}
+	s.t.Fatalf("output ended: %v", s.out.Err())
+	return nil
+}
+
+// call sends a request and returns its result, or its error code and message.
+func (s *session) call(method, params string) (result any, code int, message string) {
+	s.t.Helper()
+	s.send(`{"jsonrpc":"2.0","id":7,"method":"` + method + `","params":` + params + `}`)
+	resp := s.receive(false)
+	if resp["id"] != 7.0 {
+		s.t.Fatalf("%s: response %v has the wrong id", method, resp)
+	}
+	if e, ok := resp["error"].(map[string]any); ok {
+		return nil, int(e["code"].(float64)), e["message"].(string)
+	}
+	return resp["result"], 0, ""
+}
+
+func TestMethods(t *testing.T) {
+	s := newSession(t)
+
+	added, code, msg := s.call("add", `{"description":"Buy groceries"}`)
+	task, _ := added.(map[string]any)
+	if code != 0 || task["id"] != 1.0 || task["version"] == "" {
+		t.Fatalf("add = %v, %d %s", added, code, msg)
+	}
+	s.call("add", `{"description":"Water the plants"}`)
+
+	edited, code, msg := s.call("edit", `{"id":1,"description":"Buy bread","version":"`+task["version"].(string)+`"}`)
+	if code != 0 || edited.(map[string]any)["description"] != "Buy bread" {
+		t.Fatalf("edit = %v, %d %s", edited, code, msg)
+	}
+	if _, code, _ := s.call("complete", `{"id":1,"version":"`+task["version"].(string)+`"}`); code != CodeConflict {
+		t.Errorf("complete with a stale version: code = %d, want %d", code, CodeConflict)
+	}
+	if _, code, msg := s.call("complete", `{"id":1}`); code != 0 {
+		t.Errorf("complete: %d %s", code, msg)
+	}
+
+	if list, _, _ := s.call("list", `{}`); len(list.([]any)) != 1 {
+		t.Errorf("list = %v, want the open task only", list)
+	}
+	if list, _, _ := s.call("list", `{"all":true}`); len(list.([]any)) != 2 {
+		t.Errorf("list all = %v, want both tasks", list)
+	}
+	if found, _, _ := s.call("search", `{"query":"bread","all":true}`); len(found.([]any)) != 1 {
+		t.Errorf("search = %v, want task 1", found)
+	}
+
+	if _, code, msg := s.call("delete", `{"id":2}`); code != 0 {
+		t.Errorf("delete: %d %s", code, msg)
+	}
+	got, _, _ := s.call("get", `{"id":2}`)
+	if got.(map[string]any)["deleted_at"] == nil {
+		t.Errorf("get = %v, want a deleted task", got)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := newSession(t)
+	tests := []struct {
+		method string
+		params string
+		want   int
+	}{
+		{"get", `{"id":99}`, CodeTaskNotFound},
+		{"get", `{}`, CodeInvalidParams},
+		{"add", `{"description":""}`, CodeInvalidParams},
+		{"edit", `{"id":1,"done":true}`, CodeInvalidParams},
+		{"list", `[true]`, CodeInvalidParams},
+		{"frobnicate", `{}`, CodeMethodNotFound},
+	}
+	for _, tt := range tests {
+		if _, code, msg := s.call(tt.method, tt.params); code != tt.want {
+			t.Errorf("%s %s: code = %d (%s), want %d", tt.method, tt.params, code, msg, tt.want)
+		}
+	}
+
+	s.send(`{"jsonrpc":"2.0","id":1,`)
+	if resp := s.receive(false); resp["id"] != nil || resp["error"].(map[string]any)["code"] != float64(CodeParseError) {
+		t.Errorf("malformed JSON: response = %v, want a parse error", resp)
+	}
+	s.send(`{"id":1,"method":"list"}`)
+	if resp := s.receive(false); resp["error"].(map[string]any)["code"] != float64(CodeInvalidRequest) {
+		t.Errorf("missing jsonrpc: response = %v, want an invalid request error", resp)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	s := newSession(t)
+	// The notification in the middle of the batch gets no response.
+	s.send(`[{"jsonrpc":"2.0","id":1,"method":"add","params":{"description":"Task1"}},` +
+		`{"jsonrpc":"2.0","method":"add","params":{"description":"Task2"}},` +
+		`{"jsonrpc":"2.0","id":2,"method":"list"}]`)
+	if !s.out.Scan() {
+		t.Fatalf("output ended: %v", s.out.Err())
+	}
+	for strings.Contains(s.out.Text(), ChangedMethod) && s.out.Scan() {
+	}
+	var responses []response
+	if err := json.Unmarshal(s.out.Bytes(), &responses); err != nil {
+		t.Fatalf("invalid batch response %q: %v", s.out.Text(), err)
+	}
+	if len(responses) != 2 || string(responses[0].ID) != "1" || string(responses[1].ID) != "2" {
+		t.Errorf("batch responses = %s", s.out.Text())
+	}
+	var list []tasks.Task
+	if err := json.Unmarshal(responses[1].Result, &list); err != nil || len(list) != 2 {
+		t.Errorf("list in batch = %s, want both tasks", responses[1].Result)
+	}
+}
+
+func TestChangedNotification(t *testing.T) {
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+
+	s := newSession(t)
+	// A request round trip makes sure Serve is watching before the file changes.
+	s.call("list", `{}`)
+	if err := tasks.AddTask(s.filename, "Added elsewhere"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	if msg := s.receive(true); msg["method"] != ChangedMethod || msg["id"] != nil {
+		t.Errorf("expected a change notification, got %v", msg)
+	}
+}
@@ -0,0 +1,317 @@
+// Package server exposes the tasks of a data file as a JSON REST API. Every request
+// goes through a tasks.Client, so the server coordinates with tasker commands and
+// other processes through the same file lock.
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+//go:embed ui
+var ui embed.FS
+
+// pollInterval is how often /events checks the data file for changes.
+var pollInterval = 500 * time.Millisecond
+
+// maxBodySize bounds the request bodies the server reads.
+const maxBodySize = 1 << 20
+
+type server struct {
+	client *tasks.Client
+	// host and port are those of the address the server listens on.
+	host, port string
+}
+
+// Handler returns the REST API for the tasks of client, served on addr:
+//
+//	GET    /tasks                 list open tasks, or all tasks with ?all=true
+//	POST   /tasks                 add a task
+//	GET    /tasks/{id}            get a task
+//	PATCH  /tasks/{id}            change a task
+//	POST   /tasks/{id}/complete   mark a task as completed
+//	DELETE /tasks/{id}            move a task to the trash
+//	GET    /openapi.json          the OpenAPI document describing the above
+//	GET    /events                a stream of server-sent events, one per change of the data file
+//	GET    /                      a web UI built on the above
+//
+// Responses carry an ETag; changes to a task honour If-Match and fail with 412
+// Precondition Failed when the task has changed since it was read.
+//
+// The API has no authentication, so it only answers requests addressed to addr,
+// and changes are only accepted with a JSON Content-Type and no Origin of another
+// site: web pages open in a browser can then neither send them nor reach the server
+// through a domain name of their own.
+func Handler(client *tasks.Client, addr string) http.Handler {
+	s := &server{client: client}
+	s.host, s.port, _ = net.SplitHostPort(addr)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", s.listTasks)
+	mux.HandleFunc("POST /tasks", s.createTask)
+	mux.HandleFunc("GET /tasks/{id}", s.getTask)
+	mux.HandleFunc("PATCH /tasks/{id}", s.patchTask)
+	mux.HandleFunc("POST /tasks/{id}/complete", s.completeTask)
+	mux.HandleFunc("DELETE /tasks/{id}", s.deleteTask)
+	mux.HandleFunc("GET /openapi.json", serveOpenAPI)
+	mux.HandleFunc("GET /events", s.events)
+	static, _ := fs.Sub(ui, "ui")
+	mux.Handle("GET /", http.FileServerFS(static))
+	return s.guard(mux)
+}
+
+// guard rejects requests that may come from a web page of another site before
+// they reach next.
+func (s *server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %s is not served here", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				writeError(w, http.StatusForbidden, fmt.Errorf("requests from %s are not allowed", origin))
+				return
+			}
+		}
+		// Browsers only send other content types cross-origin without a preflight
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether host, from the Host header of a request, names the
+// address the server listens on. A loopback address may also be named localhost,
+// and one that listens on every interface by any IP address.
+func (s *server) allowedHost(host string) bool {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = host, "80"
+	}
+	if port != s.port {
+		return false
+	}
+	ip := net.ParseIP(name)
+	listenIP := net.ParseIP(s.host)
+	switch {
+	case s.host == "" || listenIP != nil && listenIP.IsUnspecified():
+		return ip != nil || strings.EqualFold(name, "localhost")
+	case s.host == "localhost" || listenIP != nil && listenIP.IsLoopback():
+		return ip != nil && ip.IsLoopback() || strings.EqualFold(name, "localhost")
+	}
+	return strings.EqualFold(name, s.host)
+}
+
+func (s *server) listTasks(w http.ResponseWriter, r *http.Request) {
+	all, err := parseBool(r.URL.Query().Get("all"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid all parameter: %w", err))
+		return
+	}
+	list, err := s.client.List(r.Context(), all)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	if list == nil {
+		list = []tasks.Task{}
+	}
+
+	etag := strconv.Quote(tasks.ListVersion(list))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *server) createTask(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Description string `json:"description"`
+	}
+	if err := readJSON(w, r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(body.Description) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("description must not be empty"))
+		return
+	}
+	task, err := s.client.Add(r.Context(), body.Description)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", task.ID))
+	writeTask(w, http.StatusCreated, task)
+}
+
+func (s *server) getTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	task, err := s.client.Get(r.Context(), id)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	writeTask(w, http.StatusOK, task)
+}
+
+func (s *server) patchTask(w http.ResponseWriter, r *http.Request) {
+	var patch tasks.TaskPatch
+	if err := readJSON(w, r, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if patch == (tasks.TaskPatch{}) {
+		writeError(w, http.StatusBadRequest, errors.New("nothing to change"))
+		return
+	}
+	s.edit(w, r, patch)
+}
+
+func (s *server) completeTask(w http.ResponseWriter, r *http.Request) {
+	completed := true
+	s.edit(w, r, tasks.TaskPatch{IsCompleted: &completed})
+}
+
+func (s *server) deleteTask(w http.ResponseWriter, r *http.Request) {
+	deleted := true
+	s.edit(w, r, tasks.TaskPatch{IsDeleted: &deleted})
+}
+
+// edit applies patch to the task named in the path, as long as it still matches
+// the If-Match header of the request, and responds with the result.
+func (s *server) edit(w http.ResponseWriter, r *http.Request, patch tasks.TaskPatch) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	task, err := s.client.Edit(r.Context(), id, patch, ifMatch(r))
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		w.Header().Set("ETag", strconv.Quote(task.Version()))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeTask(w, http.StatusOK, task)
+}
+
+// events sends a "change" event whenever the data file changes on disk, whoever
+// changed it, until the client goes away.
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	rc := http.NewResponseController(w)
+	changes := s.client.Watch(r.Context(), pollInterval)
+	// A comment makes the response start straight away, so clients know they are
+	// connected and will hear of every change from now on.
+	fmt.Fprint(w, ": watching for changes\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+	for range changes {
+		fmt.Fprint(w, "event: change\ndata: {}\n\n")
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPI)
+}
+
+func taskID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid task ID %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// ifMatch returns the task version the If-Match header of r asks for, or "" when
+// any version will do.
+func ifMatch(r *http.Request) string {
+	etag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if etag == "*" {
+		return ""
+	}
+	return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+}
+
+func parseBool(s string) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeTask(w http.ResponseWriter, status int, task tasks.Task) {
+	w.Header().Set("ETag", strconv.Quote(task.Version()))
+	writeJSON(w, status, task)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeTaskError responds to a failure of the tasks package with the matching status.
+func writeTaskError(w http.ResponseWriter, err error) {
+	var locked *tasks.ErrLocked
+	switch {
+	case errors.Is(err, tasks.ErrTaskNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, tasks.ErrConflict):
+		writeError(w, http.StatusPreconditionFailed, err)
+	case errors.As(err, &locked):
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+)
+
+// testAddr is the address the handlers under test are served on.
+const testAddr = "127.0.0.1:8080"
+
+// do sends a request to h and returns the response, decoding its body into v unless v is nil.
+// Requests are addressed to testAddr and changes are sent as JSON, unless header says otherwise.
+func do(t *testing.T, h http.Handler, method, target, body string, header http.Header, v any) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Host = testAddr
+	if method == "POST" || method == "PATCH" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: invalid response body %q: %v", method, target, rec.Body.String(), err)
+		}
+	}
+	return rec.Result()
+}
+
+func newHandler(t *testing.T) http.Handler {
+	t.Helper()
+	return Handler(tasks.New(filepath.Join(t.TempDir(), "tasks.csv")), testAddr)
+}
+
+func TestTasks(t *testing.T) {
+	h := newHandler(t)
+
+	var created tasks.Task
+	resp := do(t, h, "POST", "/tasks", `{"description":"Tidy my desk"}`, nil, &created)
+	if resp.StatusCode != http.StatusCreated || created.ID != 1 || created.Description != "Tidy my desk" {
+		t.Fatalf("POST /tasks = %d %+v", resp.StatusCode, created)
+	}
+	if got := resp.Header.Get("Location"); got != "/tasks/1" {
+		t.Errorf("Location = %q, want /tasks/1", got)
+	}
+	if got, want := resp.Header.Get("ETag"), `"`+created.Version()+`"`; got != want {
+		t.Errorf("ETag = %q, want %q", got, want)
+	}
+	do(t, h, "POST", "/tasks", `{"description":"Water the plants"}`, nil, nil)
+
+	var list []tasks.Task
+	resp = do(t, h, "GET", "/tasks", "", nil, &list)
+	if resp.StatusCode != http.StatusOK || len(list) != 2 {
+		t.Fatalf("GET /tasks = %d %+v", resp.StatusCode, list)
+	}
+	etag := resp.Header.Get("ETag")
+	resp = do(t, h, "GET", "/tasks", "", http.Header{"If-None-Match": {etag}}, nil)
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("GET /tasks with a current ETag = %d, want 304", resp.StatusCode)
+	}
+
+	var completed tasks.Task
+	resp = do(t, h, "POST", "/tasks/1/complete", "", nil, &completed)
+	if resp.StatusCode != http.StatusOK || !completed.IsCompleted {
+		t.Errorf("POST /tasks/1/complete = %d %+v", resp.StatusCode, completed)
+	}
+	resp = do(t, h, "DELETE", "/tasks/2", "", nil, nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE /tasks/2 = %d, want 204", resp.StatusCode)
+	}
+
+	resp = do(t, h, "GET", "/tasks", "", http.Header{"If-None-Match": {etag}}, &list)
+	if resp.StatusCode != http.StatusOK || len(list) != 0 {
+		t.Errorf("GET /tasks after changes = %d %+v, want no open tasks", resp.StatusCode, list)
+	}
+	do(t, h, "GET", "/tasks?all=true", "", nil, &list)
+	if len(list) != 1 || list[0].ID != 1 {
+		t.Errorf("GET /tasks?all=true = %+v, want completed task 1", list)
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	h := newHandler(t)
+	var task tasks.Task
+	resp := do(t, h, "POST", "/tasks", `{"description":"Typo"}`, nil, &task)
+	etag := resp.Header.Get("ETag")
+
+	var patched tasks.Task
+	resp = do(t, h, "PATCH", "/tasks/1", `{"description":"Fixed"}`, http.Header{"If-Match": {etag}}, &patched)
+	if resp.StatusCode != http.StatusOK || patched.Description != "Fixed" {
+		t.Fatalf("PATCH /tasks/1 = %d %+v", resp.StatusCode, patched)
+	}
+	if resp.Header.Get("ETag") == etag {
+		t.Errorf("expected the ETag to change")
+	}
+
+	var body map[string]string
+	resp = do(t, h, "PATCH", "/tasks/1", `{"is_deleted":true}`, http.Header{"If-Match": {etag}}, &body)
+	if resp.StatusCode != http.StatusPreconditionFailed || body["error"] == "" {
+		t.Errorf("PATCH with a stale ETag = %d %v, want 412", resp.StatusCode, body)
+	}
+	resp = do(t, h, "DELETE", "/tasks/1", "", http.Header{"If-Match": {etag}}, nil)
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with a stale ETag = %d, want 412", resp.StatusCode)
+	}
+
+	resp = do(t, h, "GET", "/tasks/1", "", nil, &task)
+	if resp.StatusCode != http.StatusOK || task.Description != "Fixed" || task.IsDeleted() {
+		t.Errorf("GET /tasks/1 = %d %+v", resp.StatusCode, task)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	h := newHandler(t)
+	do(t, h, "POST", "/tasks", `{"description":"Task1"}`, nil, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"unknown task", "GET", "/tasks/99", "", http.StatusNotFound},
+		{"invalid ID", "PATCH", "/tasks/one", `{"description":"x"}`, http.StatusBadRequest},
+		{"empty description", "POST", "/tasks", `{"description":" "}`, http.StatusBadRequest},
+		{"malformed body", "POST", "/tasks", `{"description":`, http.StatusBadRequest},
+		{"unknown field", "PATCH", "/tasks/1", `{"done":true}`, http.StatusBadRequest},
+		{"empty patch", "PATCH", "/tasks/1", `{}`, http.StatusBadRequest},
+		{"invalid all", "GET", "/tasks?all=maybe", "", http.StatusBadRequest},
+		{"unsupported method", "PUT", "/tasks/1", `{}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(t, h, tt.method, tt.target, tt.body, nil, nil)
+			if resp.StatusCode != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.target, resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrossSiteRequests(t *testing.T) {
+	h := newHandler(t)
+	do(t, h, "POST", "/tasks", `{"description":"Task1"}`, nil, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		host   string
+		header http.Header
+		want   int
+	}{
+		{"other host", "GET", "/tasks", "", "attacker.example:8080", nil, http.StatusForbidden},
+		{"other port", "GET", "/tasks", "", "127.0.0.1:9090", nil, http.StatusForbidden},
+		{"localhost", "GET", "/tasks", "", "localhost:8080", nil, http.StatusOK},
+		{"foreign origin", "PATCH", "/tasks/1", `{"description":"x"}`, testAddr, http.Header{"Origin": {"https://attacker.example"}}, http.StatusForbidden},
+		{"null origin", "DELETE", "/tasks/1", "", testAddr, http.Header{"Origin": {"null"}}, http.StatusForbidden},
+		{"same origin", "PATCH", "/tasks/1", `{"description":"x"}`, testAddr, http.Header{"Origin": {"http://" + testAddr}}, http.StatusOK},
+		{"text body", "POST", "/tasks", `{"description":"x"}`, testAddr, http.Header{"Content-Type": {"text/plain"}}, http.StatusUnsupportedMediaType},
+		{"form body", "PATCH", "/tasks/1", `{"description":"x"}`, testAddr, http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}, http.StatusUnsupportedMediaType},
+		{"complete without JSON", "POST", "/tasks/1/complete", "", testAddr, http.Header{"Content-Type": {""}}, http.StatusUnsupportedMediaType},
+		{"JSON with charset", "POST", "/tasks/1/complete", "", testAddr, http.Header{"Content-Type": {"application/json; charset=utf-8"}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Host = tt.host
+			if tt.method == "POST" || tt.method == "PATCH" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.target, rec.Code, rec.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowedHost(t *testing.T) {
+	tests := []struct {
+		addr, host string
+		want       bool
+	}{
+		{"127.0.0.1:8080", "127.0.0.1:8080", true},
+		{"127.0.0.1:8080", "localhost:8080", true},
+		{"127.0.0.1:8080", "[::1]:8080", true},
+		{"127.0.0.1:8080", "rebind.attacker.example:8080", false},
+		{"127.0.0.1:8080", "192.168.1.5:8080", false},
+		{"0.0.0.0:8080", "192.168.1.5:8080", true},
+		{"[::]:8080", "myhost.example:8080", false},
+		{"192.168.1.5:80", "192.168.1.5", true},
+		{"tasks.lan:8080", "TASKS.lan:8080", true},
+	}
+	for _, tt := range tests {
+		s := &server{}
+		s.host, s.port, _ = net.SplitHostPort(tt.addr)
+		if got := s.allowedHost(tt.host); got != tt.want {
+			t.Errorf("allowedHost(%q) on %s = %v, want %v", tt.host, tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	resp := do(t, newHandler(t), "GET", "/openapi.json", "", nil, &doc)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Fatalf("GET /openapi.json = %d %+v", resp.StatusCode, doc)
+	}
+	for _, path := range []string{"/tasks", "/tasks/{id}", "/tasks/{id}/complete"} {
+		if _, ok := doc.Paths[path]; !ok {
+			t.Errorf("expected %s in the OpenAPI document", path)
+		}
+	}
+}
+
+func TestUI(t *testing.T) {
+	h := newHandler(t)
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp := do(t, h, "GET", path, "", nil, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", path, resp.StatusCode)
+		}
+	}
+	if resp := do(t, h, "GET", "/missing.js", "", nil, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /missing.js = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Config.Handler = Handler(tasks.New(tmpFile), srv.Listener.Addr().String())
+	srv.Start()
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events error: %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", got)
+	}
+
+	// Change the data file the way another process would, outside the server.
+	if err := tasks.AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if scanner.Text() == "event: change" {
+			return
+		}
+	}
+	t.Errorf("expected a change event, stream ended with %v", scanner.Err())
+}
@@ -0,0 +1,160 @@
+// The tasker web UI. It talks to the REST API of the same server and reloads the
+// list whenever /events reports that the data file changed.
+"use strict";
+
+const list = document.getElementById("tasks");
+const template = document.getElementById("task");
+const search = document.getElementById("search");
+const status = document.getElementById("status");
+let tasks = [];
+
+function showStatus(message, isError) {
+  status.textContent = message;
+  status.classList.toggle("error", Boolean(isError));
+}
+
+async function request(method, path, body, etag) {
+  const headers = {};
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+  if (etag) headers["If-Match"] = etag;
+  const resp = await fetch(path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (!resp.ok) {
+    const data = await resp.json().catch(() => ({}));
+    const err = new Error(data.error || resp.statusText);
+    err.status = resp.status;
+    throw err;
+  }
+  return resp;
+}
+
+async function load() {
+  try {
+    const resp = await request("GET", "/tasks?all=true");
+    tasks = await resp.json();
+    render();
+  } catch (err) {
+    showStatus("Failed to load tasks: " + err.message, true);
+  }
+}
+
+function shown(task) {
+  const show = document.querySelector("input[name=show]:checked").value;
+  if (show === "open" && task.is_completed) return false;
+  if (show === "completed" && !task.is_completed) return false;
+  const filter = search.value.trim().toLowerCase();
+  return !filter || task.description.toLowerCase().includes(filter);
+}
+
+function render() {
+  if (list.querySelector(".description input")) return; // don't interrupt an edit
+
+  list.replaceChildren();
+  for (const task of tasks.filter(shown)) {
+    const item = template.content.firstElementChild.cloneNode(true);
+    item.classList.toggle("completed", task.is_completed);
+    item.querySelector(".id").textContent = "#" + task.id;
+    item.querySelector(".description").textContent = task.description;
+    const created = item.querySelector(".created");
+    created.dateTime = task.created_at;
+    created.textContent = new Date(task.created_at).toLocaleString();
+
+    const done = item.querySelector(".done");
+    done.checked = task.is_completed;
+    done.addEventListener("change", () => change(task, { is_completed: done.checked }));
+    item.querySelector(".delete").addEventListener("click", () => remove(task));
+    item.querySelector(".edit").addEventListener("click", () => edit(item, task));
+    item.querySelector(".description").addEventListener("dblclick", () => edit(item, task));
+    list.append(item);
+  }
+  document.getElementById("empty").hidden = list.children.length > 0;
+}
+
+// etagOf fetches the task again, so a change is only made to the version the
+// user is looking at.
+async function etagOf(task) {
+  const resp = await request("GET", "/tasks/" + task.id);
+  const current = await resp.json();
+  if (current.description !== task.description || current.is_completed !== task.is_completed) {
+    const err = new Error("the task was changed elsewhere");
+    err.status = 412;
+    throw err;
+  }
+  return resp.headers.get("ETag");
+}
+
+async function change(task, patch) {
+  try {
+    await request("PATCH", "/tasks/" + task.id, patch, await etagOf(task));
+    showStatus("");
+  } catch (err) {
+    showStatus(err.status === 412
+      ? "Task #" + task.id + " was changed elsewhere; showing the latest version."
+      : "Failed to change task: " + err.message, true);
+  }
+  load();
+}
+
+async function remove(task) {
+  try {
+    await request("DELETE", "/tasks/" + task.id, undefined, await etagOf(task));
+    showStatus("Task #" + task.id + " moved to the trash.");
+  } catch (err) {
+    showStatus("Failed to delete task: " + err.message, true);
+  }
+  load();
+}
+
+function edit(item, task) {
+  const description = item.querySelector(".description");
+  if (description.querySelector("input")) return;
+  const input = document.createElement("input");
+  input.value = task.description;
+  description.replaceChildren(input);
+  input.focus();
+
+  let finished = false;
+  const finish = (save) => {
+    if (finished) return;
+    finished = true;
+    description.replaceChildren();
+    const value = input.value.trim();
+    if (save && value && value !== task.description) {
+      change(task, { description: value });
+    } else {
+      render();
+    }
+  };
+  input.addEventListener("keydown", (e) => {
+    if (e.key === "Enter") finish(true);
+    if (e.key === "Escape") finish(false);
+  });
+  input.addEventListener("blur", () => finish(true));
+}
+
+document.getElementById("add").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const input = document.getElementById("description");
+  try {
+    await request("POST", "/tasks", { description: input.value });
+    input.value = "";
+    showStatus("");
+  } catch (err) {
+    showStatus("Failed to add task: " + err.message, true);
+  }
+  load();
+});
+
+search.addEventListener("input", render);
+for (const radio of document.querySelectorAll("input[name=show]")) {
+  radio.addEventListener("change", render);
+}
+
+const events = new EventSource("/events");
+events.addEventListener("change", load);
+events.addEventListener("open", load);
+
+load();
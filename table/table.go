@@ -0,0 +1,276 @@
+// Package table renders tasks as an aligned table for terminals: only the chosen
+// columns, the description fitted to the width of the terminal, and completed tasks
+// set apart by color where color is wanted.
+package table
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/mattn/go-runewidth"
+	"github.com/mergestat/timediff"
+)
+
+// Column is a column of the table.
+type Column struct {
+	// Name selects the column in ParseColumns.
+	Name   string
+	Header string
+	// Flexible columns are truncated or wrapped to fit the table into its width.
+	Flexible bool
+	value    func(tasks.Task) string
+}
+
+// when formats a time relative to now, or nothing for the zero time.
+func when(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return timediff.TimeDiff(t)
+}
+
+// Columns are the columns a table can show.
+var Columns = []Column{
+	{Name: "id", Header: "ID", value: func(t tasks.Task) string { return strconv.Itoa(t.ID) }},
+	{Name: "desc", Header: "Description", Flexible: true, value: func(t tasks.Task) string { return t.Description }},
+	{Name: "created", Header: "Created", value: func(t tasks.Task) string { return when(t.CreatedAt) }},
+	{Name: "done", Header: "Done", value: func(t tasks.Task) string {
+		if t.IsCompleted {
+			return "✓"
+		}
+		return ""
+	}},
+	{Name: "completed", Header: "Completed", value: func(t tasks.Task) string { return when(t.CompletedAt) }},
+	{Name: "deleted", Header: "Deleted", value: func(t tasks.Task) string { return when(t.DeletedAt) }},
+	{Name: "uuid", Header: "UUID", value: func(t tasks.Task) string { return t.UUID }},
+}
+
+// DefaultColumns are shown unless others are chosen.
+const DefaultColumns = "id,desc,created,done"
+
+// ParseColumns returns the columns named in a comma-separated list such as "id,desc".
+func ParseColumns(spec string) ([]Column, error) {
+	var cols []Column
+	for name := range strings.SplitSeq(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		i := slices.IndexFunc(Columns, func(c Column) bool { return c.Name == name })
+		if i < 0 {
+			names := make([]string, len(Columns))
+			for i, c := range Columns {
+				names[i] = c.Name
+			}
+			return nil, fmt.Errorf("unknown column %q (expected %s)", name, strings.Join(names, ", "))
+		}
+		cols = append(cols, Columns[i])
+	}
+	return cols, nil
+}
+
+// ColorEnabled decides whether to color output for a --color mode of auto, always
+// or never. Auto colors a terminal unless NO_COLOR is set or TERM is dumb.
+func ColorEnabled(mode string, isTerminal bool) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return isTerminal && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb", nil
+	}
+	return false, fmt.Errorf("invalid color mode %q (expected auto, always or never)", mode)
+}
+
+// ANSI escape sequences for the styles of a table.
+const (
+	styleHeader = "\033[1;4m"
+	styleFaint  = "\033[2m"
+	styleDone   = "\033[32m"
+	styleReset  = "\033[0m"
+)
+
+// minFlexibleWidth is the narrowest a flexible column is made to fit the table.
+const minFlexibleWidth = 10
+
+// columnGap separates the columns.
+const columnGap = "  "
+
+// Options configure a Writer.
+type Options struct {
+	Columns []Column
+	// Width is the width to fit the table into, or zero for no limit.
+	Width int
+	// Wrap wraps flexible columns onto more lines instead of truncating them.
+	Wrap  bool
+	Color bool
+}
+
+// Writer writes tasks as a table. Rows are buffered until Flush. The first Flush
+// sizes the columns to the rows added so far, and later ones keep those widths so
+// the columns stay aligned throughout.
+type Writer struct {
+	w    io.Writer
+	opts Options
+	rows []row
+	// widths are the column widths set by the first Flush.
+	widths []int
+}
+
+type row struct {
+	cells     []string
+	completed bool
+}
+
+// NewWriter returns a Writer for w.
+func NewWriter(w io.Writer, opts Options) *Writer {
+	return &Writer{w: w, opts: opts}
+}
+
+// Add adds a task to the table.
+func (t *Writer) Add(task tasks.Task) {
+	cells := make([]string, len(t.opts.Columns))
+	for i, col := range t.opts.Columns {
+		// Keep every row on its own line unless it is wrapped
+		cells[i] = strings.Join(strings.Fields(col.value(task)), " ")
+	}
+	t.rows = append(t.rows, row{cells: cells, completed: task.IsCompleted})
+}
+
+// Flush writes the rows added so far, preceded by the header the first time.
+func (t *Writer) Flush() error {
+	var b strings.Builder
+	if t.widths == nil {
+		cols := t.opts.Columns
+		header := make([]string, len(cols))
+		for i, col := range cols {
+			header[i] = col.Header
+		}
+		t.widths = t.measure(header)
+		t.writeRow(&b, header, styleHeader)
+	}
+	for _, r := range t.rows {
+		style := ""
+		if r.completed {
+			style = styleFaint
+		}
+		t.writeRow(&b, r.cells, style)
+	}
+	t.rows = t.rows[:0]
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+// measure returns the width of each column: as wide as its widest cell, except
+// that flexible columns shrink to fit the table into the width of the Writer.
+func (t *Writer) measure(header []string) []int {
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = runewidth.StringWidth(h)
+	}
+	for _, r := range t.rows {
+		for i, cell := range r.cells {
+			widths[i] = max(widths[i], runewidth.StringWidth(cell))
+		}
+	}
+	if t.opts.Width <= 0 {
+		return widths
+	}
+
+	total := len(columnGap) * (len(widths) - 1)
+	flexible := 0
+	for i, w := range widths {
+		total += w
+		if t.opts.Columns[i].Flexible {
+			flexible++
+		}
+	}
+	for i, col := range t.opts.Columns {
+		if over := total - t.opts.Width; over > 0 && col.Flexible {
+			shrunk := max(widths[i]-(over+flexible-1)/flexible, min(widths[i], minFlexibleWidth))
+			total -= widths[i] - shrunk
+			widths[i] = shrunk
+		}
+	}
+	return widths
+}
+
+// writeRow writes the cells of a row, each padded to its column's width, with
+// flexible cells truncated or wrapped onto continuation lines to fit the width of
+// the Writer. Other cells wider than their column, which can only come after the
+// first Flush, are written in full.
+func (t *Writer) writeRow(b *strings.Builder, cells []string, style string) {
+	widths := t.widths
+	lines := make([][]string, len(cells))
+	height := 1
+	for i, cell := range cells {
+		switch {
+		case runewidth.StringWidth(cell) <= widths[i] || !t.opts.Columns[i].Flexible || t.opts.Width <= 0:
+			lines[i] = []string{cell}
+		case t.opts.Wrap:
+			lines[i] = wrap(cell, widths[i])
+		default:
+			lines[i] = []string{runewidth.Truncate(cell, widths[i], "…")}
+		}
+		height = max(height, len(lines[i]))
+	}
+
+	for line := range height {
+		var text strings.Builder
+		for i := range cells {
+			cell := ""
+			if line < len(lines[i]) {
+				cell = lines[i][line]
+			}
+			if i > 0 {
+				text.WriteString(columnGap)
+			}
+			if i == len(cells)-1 {
+				text.WriteString(cell)
+			} else {
+				text.WriteString(runewidth.FillRight(cell, widths[i]))
+			}
+		}
+		b.WriteString(t.style(strings.TrimRight(text.String(), " "), style))
+		b.WriteByte('\n')
+	}
+}
+
+// wrap breaks text into lines of at most width, between words where it can.
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && runewidth.StringWidth(line)+1+runewidth.StringWidth(word) <= width {
+			line += " " + word
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		// Break words that do not fit on a line of their own
+		for runewidth.StringWidth(word) > width {
+			part := runewidth.Truncate(word, width, "")
+			lines = append(lines, part)
+			word = word[len(part):]
+		}
+		line = word
+	}
+	return append(lines, line)
+}
+
+// style wraps a line of text in the escape sequences of style, if colored.
+func (t *Writer) style(text, style string) string {
+	if !t.opts.Color || style == "" {
+		return text
+	}
+	if style == styleFaint {
+		// Completed rows are faint, with their check mark in green
+		text = strings.ReplaceAll(text, "✓", styleDone+"✓"+styleReset+styleFaint)
+	}
+	return style + text + styleReset
+}
@@ -0,0 +1,181 @@
+package table
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CreepySunny/tasker/tasks"
+)
+
+var testTasks = []tasks.Task{
+	{ID: 1, Description: "Buy groceries"},
+	{ID: 2, Description: "Water the plants in the garden and on the balcony", IsCompleted: true},
+	{ID: 10, Description: "Call\tthe bank"},
+}
+
+func render(t *testing.T, opts Options) string {
+	t.Helper()
+	var b strings.Builder
+	w := NewWriter(&b, opts)
+	for _, task := range testTasks {
+		w.Add(task)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+	return b.String()
+}
+
+func columns(t *testing.T, spec string) []Column {
+	t.Helper()
+	cols, err := ParseColumns(spec)
+	if err != nil {
+		t.Fatalf("ParseColumns(%q) error: %v", spec, err)
+	}
+	return cols
+}
+
+func TestParseColumns(t *testing.T) {
+	cols := columns(t, "ID, desc,done")
+	var names []string
+	for _, c := range cols {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, ","); got != "id,desc,done" {
+		t.Errorf("ParseColumns = %s, want id,desc,done", got)
+	}
+
+	_, err := ParseColumns("id,tags")
+	if err == nil || !strings.Contains(err.Error(), `"tags"`) {
+		t.Errorf("ParseColumns(id,tags) error = %v, want unknown column", err)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "aligned",
+			opts: Options{Columns: columns(t, "id,desc,done")},
+			want: "" +
+				"ID  Description                                        Done\n" +
+				"1   Buy groceries\n" +
+				"2   Water the plants in the garden and on the balcony  ✓\n" +
+				"10  Call the bank\n",
+		},
+		{
+			name: "truncated",
+			opts: Options{Columns: columns(t, "id,desc,done"), Width: 30},
+			want: "" +
+				"ID  Description           Done\n" +
+				"1   Buy groceries\n" +
+				"2   Water the plants in…  ✓\n" +
+				"10  Call the bank\n",
+		},
+		{
+			name: "wrapped",
+			opts: Options{Columns: columns(t, "desc,id"), Width: 24, Wrap: true},
+			want: "" +
+				"Description           ID\n" +
+				"Buy groceries         1\n" +
+				"Water the plants in   2\n" +
+				"the garden and on\n" +
+				"the balcony\n" +
+				"Call the bank         10\n",
+		},
+		{
+			name: "narrowest",
+			opts: Options{Columns: columns(t, "id,desc"), Width: 5},
+			want: "" +
+				"ID  Descripti…\n" +
+				"1   Buy groce…\n" +
+				"2   Water the…\n" +
+				"10  Call the …\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.opts); got != tt.want {
+				t.Errorf("table =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterColor(t *testing.T) {
+	got := render(t, Options{Columns: columns(t, "id,done"), Color: true})
+	want := "" +
+		styleHeader + "ID  Done" + styleReset + "\n" +
+		"1\n" +
+		styleFaint + "2   " + styleDone + "✓" + styleReset + styleFaint + styleReset + "\n" +
+		"10\n"
+	if got != want {
+		t.Errorf("table = %q, want %q", got, want)
+	}
+}
+
+func TestWriterHeaderOnce(t *testing.T) {
+	var b strings.Builder
+	w := NewWriter(&b, Options{Columns: columns(t, "id")})
+	for _, task := range testTasks {
+		w.Add(task)
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush error: %v", err)
+		}
+	}
+	if got, want := b.String(), "ID\n1\n2\n10\n"; got != want {
+		t.Errorf("table = %q, want %q", got, want)
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		mode, noColor, term string
+		tty                 bool
+		want                bool
+	}{
+		{"auto", "", "xterm", true, true},
+		{"auto", "", "xterm", false, false},
+		{"auto", "1", "xterm", true, false},
+		{"auto", "", "dumb", true, false},
+		{"always", "1", "dumb", false, true},
+		{"never", "", "xterm", true, false},
+	}
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.noColor)
+		t.Setenv("TERM", tt.term)
+		got, err := ColorEnabled(tt.mode, tt.tty)
+		if err != nil {
+			t.Fatalf("ColorEnabled(%q) error: %v", tt.mode, err)
+		}
+		if got != tt.want {
+			t.Errorf("ColorEnabled(%q, %v) with NO_COLOR=%q TERM=%q = %v, want %v",
+				tt.mode, tt.tty, tt.noColor, tt.term, got, tt.want)
+		}
+	}
+	if _, err := ColorEnabled("sometimes", true); err == nil {
+		t.Error("ColorEnabled(sometimes) succeeded, want error")
+	}
+}
+
+func TestWriterKeepsWidths(t *testing.T) {
+	var b strings.Builder
+	w := NewWriter(&b, Options{Columns: columns(t, "desc,id"), Width: 20})
+	for _, task := range testTasks {
+		w.Add(task)
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush error: %v", err)
+		}
+	}
+	want := "" +
+		"Description    ID\n" +
+		"Buy groceries  1\n" +
+		"Water the pl…  2\n" +
+		"Call the bank  10\n"
+	if got := b.String(); got != want {
+		t.Errorf("table =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,185 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// archivePath returns the file completed tasks are archived to, e.g. tasks.archive.csv for tasks.csv.
+func archivePath(filename string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + ".archive" + ext
+}
+
+// readArchive reads the archive of filename. It must be called while the datasource lock is held.
+func readArchive(filename string) ([]Task, error) {
+	data, err := os.ReadFile(archivePath(filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	tasks, err := decodeTasks(archivePath(filename), data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse archive: %w", err)
+	}
+	return tasks, nil
+}
+
+// writeArchive atomically replaces the archive of filename.
+func writeArchive(filename string, tasks []Task) error {
+	if err := checkFormat(archivePath(filename)); err != nil {
+		return err
+	}
+	return replaceFile(archivePath(filename), func(f *os.File) error {
+		return encodeTasks(archivePath(filename), f, tasks)
+	})
+}
+
+// completionTime returns when the task was completed. Files written before
+// CompletedAt existed stamped CreatedAt on completion instead.
+func completionTime(task Task) time.Time {
+	if task.CompletedAt.IsZero() {
+		return task.CreatedAt
+	}
+	return task.CompletedAt
+}
+
+// ArchiveTasks moves tasks completed longer than olderThan ago (all completed tasks
+// when olderThan is zero) from filename into its archive and returns how many were moved.
+func ArchiveTasks(filename string, olderThan time.Duration) (int, error) {
+	return New(filename).Archive(context.Background(), olderThan)
+}
+
+// Archive moves tasks completed longer than olderThan ago (all completed tasks when
+// olderThan is zero) into the archive and returns how many were moved. The archive
+// is written before the data file, so an interrupted run never loses tasks.
+func (c *Client) Archive(ctx context.Context, olderThan time.Duration) (int, error) {
+	return c.archive(ctx, "archive", olderThan)
+}
+
+// opAutoArchive is recorded in the audit history for automatic archiving. It is
+// not journaled, so undo keeps reverting the command the archiving followed.
+const opAutoArchive = "auto-archive"
+
+// AutoArchiveTasks archives like ArchiveTasks, for use right after another change.
+// It is not recorded as a step of its own in the undo journal.
+func AutoArchiveTasks(filename string, olderThan time.Duration) (int, error) {
+	return New(filename).AutoArchive(context.Background(), olderThan)
+}
+
+// AutoArchive archives like Archive, for use right after another change. It is
+// not recorded as a step of its own in the undo journal: undoing the change before
+// it brings the tasks it touched back out of the archive instead.
+func (c *Client) AutoArchive(ctx context.Context, olderThan time.Duration) (int, error) {
+	return c.archive(ctx, opAutoArchive, olderThan)
+}
+
+func (c *Client) archive(ctx context.Context, op string, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	archived := 0
+	err := c.update(ctx, op, func(tasks []Task) ([]Task, error) {
+		var moved []Task
+		kept := slices.DeleteFunc(tasks, func(t Task) bool {
+			if t.IsCompleted && !t.IsDeleted() && !completionTime(t).After(cutoff) {
+				moved = append(moved, t)
+				return true
+			}
+			return false
+		})
+		if len(moved) == 0 {
+			return kept, nil
+		}
+		if err := addToArchive(c.filename, moved); err != nil {
+			return nil, err
+		}
+		archived = len(moved)
+		return kept, nil
+	})
+	return archived, err
+}
+
+// addToArchive merges tasks into the archive, replacing any archived task with the same ID.
+func addToArchive(filename string, tasks []Task) error {
+	archived, err := readArchive(filename)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		archived = slices.DeleteFunc(archived, func(t Task) bool { return t.ID == task.ID })
+		archived = append(archived, task)
+	}
+	slices.SortFunc(archived, func(a, b Task) int { return a.ID - b.ID })
+	return writeArchive(filename, archived)
+}
+
+// replayArchive moves the tasks of an archive journal entry back out of the
+// archive (undo) or into it again (redo).
+func replayArchive(filename string, changes []Change, undo bool) error {
+	if !undo {
+		tasks := make([]Task, 0, len(changes))
+		for _, change := range changes {
+			if change.Before != nil {
+				tasks = append(tasks, *change.Before)
+			}
+		}
+		return addToArchive(filename, tasks)
+	}
+
+	archived, err := readArchive(filename)
+	if err != nil {
+		return err
+	}
+	archived = slices.DeleteFunc(archived, func(t Task) bool {
+		return slices.ContainsFunc(changes, func(c Change) bool { return c.ID == t.ID })
+	})
+	return writeArchive(filename, archived)
+}
+
+// unarchive moves the tasks changed by a journal entry back out of the archive, if
+// they were archived automatically after the entry was recorded, and returns them.
+func unarchive(filename string, tasks []Task, changes []Change) ([]Task, error) {
+	archived, err := readArchive(filename)
+	if err != nil {
+		return nil, err
+	}
+	kept := slices.DeleteFunc(slices.Clone(archived), func(a Task) bool {
+		return slices.ContainsFunc(changes, func(c Change) bool { return c.ID == a.ID }) &&
+			!slices.ContainsFunc(tasks, func(t Task) bool { return t.ID == a.ID })
+	})
+	if len(kept) == len(archived) {
+		return tasks, nil
+	}
+	if err := writeArchive(filename, kept); err != nil {
+		return nil, err
+	}
+	for _, a := range archived {
+		if !slices.ContainsFunc(kept, func(k Task) bool { return k.ID == a.ID }) {
+			tasks = append(tasks, a)
+		}
+	}
+	return tasks, nil
+}
+
+// ListArchivedTasks returns the tasks that have been moved to the archive.
+func ListArchivedTasks(filename string) ([]Task, error) {
+	return New(filename).Archived(context.Background())
+}
+
+// Archived returns the tasks that have been moved to the archive.
+func (c *Client) Archived(ctx context.Context) (_ []Task, err error) {
+	file, err := c.open(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer closeLocked(file, &err)
+
+	return readArchive(c.filename)
+}
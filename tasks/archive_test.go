@@ -0,0 +1,133 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchivePath(t *testing.T) {
+	if got := archivePath("/data/tasks.csv"); got != "/data/tasks.archive.csv" {
+		t.Errorf("archivePath() = %q, want %q", got, "/data/tasks.archive.csv")
+	}
+}
+
+func TestArchiveTasks(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	content := csvHeader +
+		"1,Old,2025-05-01T10:00:00Z,true,,2025-05-02T10:00:00Z\n" +
+		"2,Open,2025-05-01T10:00:00Z,false,,\n" +
+		"3,Legacy,2025-05-03T10:00:00Z,true\n" +
+		"4,Recent,2025-05-01T10:00:00Z,true,," + time.Now().Format(time.RFC3339) + "\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	t.Run("archives tasks completed before the cutoff", func(t *testing.T) {
+		archived, err := ArchiveTasks(tmpFile, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("ArchiveTasks error: %v", err)
+		}
+		if archived != 2 {
+			t.Errorf("expected 2 tasks archived, got %d", archived)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 2 || got[0] != 2 || got[1] != 4 {
+			t.Errorf("expected tasks 2 and 4 to remain, got %v", got)
+		}
+		inArchive, err := ListArchivedTasks(tmpFile)
+		if err != nil {
+			t.Fatalf("ListArchivedTasks error: %v", err)
+		}
+		if got := taskIDs(inArchive); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+			t.Errorf("expected tasks 1 and 3 archived, got %v", got)
+		}
+	})
+
+	t.Run("archived tasks are still found by GetTask", func(t *testing.T) {
+		task, err := GetTask(tmpFile, "1")
+		if err != nil || task.Description != "Old" {
+			t.Errorf("GetTask() = %+v, %v", task, err)
+		}
+	})
+
+	t.Run("undo moves tasks back out of the archive", func(t *testing.T) {
+		if _, err := Undo(tmpFile); err != nil {
+			t.Fatalf("Undo error: %v", err)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 4 {
+			t.Errorf("expected all 4 tasks back, got %v", got)
+		}
+		inArchive, err := ListArchivedTasks(tmpFile)
+		if err != nil {
+			t.Fatalf("ListArchivedTasks error: %v", err)
+		}
+		if len(inArchive) != 0 {
+			t.Errorf("expected empty archive after undo, got %+v", inArchive)
+		}
+	})
+
+	t.Run("nothing to archive leaves the file untouched", func(t *testing.T) {
+		before, err := os.ReadFile(tmpFile)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if _, err := ArchiveTasks(tmpFile, 1000*24*time.Hour); err != nil {
+			t.Fatalf("ArchiveTasks error: %v", err)
+		}
+		after, err := os.ReadFile(tmpFile)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if string(before) != string(after) {
+			t.Errorf("expected file to be unchanged")
+		}
+	})
+}
+
+func TestAutoArchive(t *testing.T) {
+	for _, name := range []string{"tasks.csv", "tasks.jsonl"} {
+		t.Run(name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), name)
+			for _, desc := range []string{"Task1", "Task2"} {
+				if err := AddTask(tmpFile, desc); err != nil {
+					t.Fatalf("AddTask error: %v", err)
+				}
+			}
+			if err := CompleteTask(tmpFile, "1"); err != nil {
+				t.Fatalf("CompleteTask error: %v", err)
+			}
+			if archived, err := AutoArchiveTasks(tmpFile, 0); err != nil || archived != 1 {
+				t.Fatalf("AutoArchiveTasks = %d, %v; want 1 archived", archived, err)
+			}
+
+			entry, err := Undo(tmpFile)
+			if err != nil || entry.Op != "complete" {
+				t.Fatalf("Undo = %+v, %v; want the complete undone", entry, err)
+			}
+			open, err := ListTasks(tmpFile, false)
+			if err != nil {
+				t.Fatalf("ListTasks error: %v", err)
+			}
+			if got := taskIDs(open); len(got) != 2 {
+				t.Errorf("expected both tasks open after undo, got %v", got)
+			}
+			if inArchive, err := ListArchivedTasks(tmpFile); err != nil || len(inArchive) != 0 {
+				t.Errorf("expected an empty archive after undo, got %+v, %v", inArchive, err)
+			}
+
+			events, err := TaskHistory(tmpFile, "1")
+			if err != nil {
+				t.Fatalf("TaskHistory error: %v", err)
+			}
+			var ops []string
+			for _, event := range events {
+				ops = append(ops, event.Op)
+			}
+			if got := strings.Join(ops, ","); got != "add,complete,auto-archive,undo" {
+				t.Errorf("history = %s, want add,complete,auto-archive,undo", got)
+			}
+		})
+	}
+}
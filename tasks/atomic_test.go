@@ -0,0 +1,106 @@
+package tasks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withFailingStep replaces one file operation of replaceFile with a failing one for the test.
+func withFailingStep(t *testing.T, step string) {
+	t.Helper()
+	errInjected := errors.New("injected failure")
+	origCreate, origSync, origRename := createTemp, syncFile, renameFile
+	t.Cleanup(func() { createTemp, syncFile, renameFile = origCreate, origSync, origRename })
+
+	switch step {
+	case "create":
+		createTemp = func(dir, pattern string) (*os.File, error) { return nil, errInjected }
+	case "sync":
+		syncFile = func(f *os.File) error { return errInjected }
+	case "rename":
+		renameFile = func(oldpath, newpath string) error { return errInjected }
+	}
+}
+
+func TestReplaceFileFailuresKeepOriginal(t *testing.T) {
+	original := csvHeader + "1,Task1,2025-05-12T10:00:00Z,false,,,\n"
+
+	for _, step := range []string{"create", "write", "sync", "rename"} {
+		t.Run(step, func(t *testing.T) {
+			dir := t.TempDir()
+			tmpFile := filepath.Join(dir, "tasks.csv")
+			if err := os.WriteFile(tmpFile, []byte(original), 0644); err != nil {
+				t.Fatalf("failed to write temp file: %v", err)
+			}
+
+			withFailingStep(t, step)
+			err := replaceFile(tmpFile, func(f *os.File) error {
+				if _, err := f.WriteString("partial"); err != nil {
+					return err
+				}
+				if step == "write" {
+					return errors.New("disk full")
+				}
+				return nil
+			})
+			if err == nil {
+				t.Fatalf("expected replaceFile to fail at %s", step)
+			}
+
+			data, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+			if string(data) != original {
+				t.Errorf("expected original content to be kept, got %q", data)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("failed to read dir: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("expected temporary file to be cleaned up, got %v", entries)
+			}
+		})
+	}
+}
+
+func TestReplaceFileKeepsMode(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := os.WriteFile(tmpFile, []byte(csvHeader), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if err := replaceFile(tmpFile, func(f *os.File) error { return writeTasksToFile(f, nil) }); err != nil {
+		t.Fatalf("replaceFile error: %v", err)
+	}
+	info, err := os.Stat(tmpFile)
+	if err != nil {
+		t.Fatalf("failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600 to be kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestCompleteTaskFailureLeavesFileIntact(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	original := csvHeader + "1,Task1,2025-05-12T10:00:00Z,false,,,6f1c9c52-8d4e-4b71-9a0e-2c7d1f3b5a64\n"
+	if err := os.WriteFile(tmpFile, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	withFailingStep(t, "rename")
+	err := CompleteTask(tmpFile, "1")
+	if err == nil || !strings.Contains(err.Error(), "failed to replace file") {
+		t.Fatalf("expected rename failure, got %v", err)
+	}
+	if tasks := readAllTasks(t, tmpFile); tasks[0].IsCompleted {
+		t.Errorf("expected task to remain uncompleted after failed write")
+	}
+	if entries, _ := UndoList(tmpFile); len(entries) != 0 {
+		t.Errorf("expected failed write not to be journaled, got %+v", entries)
+	}
+}
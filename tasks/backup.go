@@ -0,0 +1,269 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BackupKeep is how many snapshots of the data file are kept. A snapshot is taken
+// before every rewrite of the data file; zero disables automatic snapshots.
+var BackupKeep = 10
+
+// BackupMaxAge removes snapshots older than this. Zero keeps them regardless of age.
+var BackupMaxAge time.Duration
+
+// backupTimeFormat names snapshot files so they sort chronologically.
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// ErrNoBackup is returned when no snapshot matches the requested time.
+var ErrNoBackup = errors.New("no backup found")
+
+// Backup is a snapshot of the data file.
+type Backup struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+func backupDir(filename string) string {
+	return filename + ".backups"
+}
+
+// ListBackups returns the snapshots of the data file, oldest first.
+func ListBackups(filename string) ([]Backup, error) {
+	return New(filename).Backups(context.Background())
+}
+
+// Backups returns the snapshots of the data file, oldest first.
+func (c *Client) Backups(ctx context.Context) (_ []Backup, err error) {
+	file, err := c.open(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer closeLocked(file, &err)
+
+	return listBackups(c.filename)
+}
+
+func listBackups(filename string) ([]Backup, error) {
+	entries, err := os.ReadDir(backupDir(filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		stamp, ok := strings.CutSuffix(entry.Name(), filepath.Ext(filename))
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backups: %w", err)
+		}
+		backups = append(backups, Backup{
+			Path: filepath.Join(backupDir(filename), entry.Name()),
+			Time: t,
+			Size: info.Size(),
+		})
+	}
+	slices.SortFunc(backups, func(a, b Backup) int { return a.Time.Compare(b.Time) })
+	return backups, nil
+}
+
+// CreateBackup takes a snapshot of the data file now.
+func CreateBackup(filename string) (Backup, error) {
+	return New(filename).CreateBackup(context.Background())
+}
+
+// CreateBackup takes a snapshot of the data file now.
+func (c *Client) CreateBackup(ctx context.Context) (_ Backup, err error) {
+	file, err := c.open(ctx, true)
+	if err != nil {
+		return Backup{}, fmt.Errorf("failed to open datasource for backup: %w", err)
+	}
+	defer closeLocked(file, &err)
+
+	backup, err := createBackup(c.filename)
+	if err != nil {
+		return Backup{}, err
+	}
+	return backup, c.rotateBackups()
+}
+
+// createBackup copies the data file into the backup directory. It must be called
+// while the datasource lock is held.
+func createBackup(filename string) (Backup, error) {
+	src, err := os.Open(filename)
+	if err != nil {
+		return Backup{}, fmt.Errorf("failed to open file for backup: %w", err)
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(backupDir(filename), 0755); err != nil {
+		return Backup{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	now := time.Now().UTC()
+	backup := Backup{
+		Path: filepath.Join(backupDir(filename), now.Format(backupTimeFormat)+filepath.Ext(filename)),
+		Time: now,
+	}
+	err = replaceFile(backup.Path, func(f *os.File) error {
+		backup.Size, err = io.Copy(f, src)
+		return err
+	})
+	if err != nil {
+		return Backup{}, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return backup, nil
+}
+
+// rotateBackups deletes snapshots beyond the number the client keeps and older
+// than its maximum age.
+func (c *Client) rotateBackups() error {
+	backups, err := listBackups(c.filename)
+	if err != nil {
+		return err
+	}
+	for i, backup := range backups {
+		tooMany := c.backupKeep > 0 && i < len(backups)-c.backupKeep
+		tooOld := c.backupMaxAge > 0 && time.Since(backup.Time) > c.backupMaxAge
+		if tooMany || tooOld {
+			if err := os.Remove(backup.Path); err != nil {
+				return fmt.Errorf("failed to remove old backup: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// autoBackup snapshots the data file before it is rewritten, unless the client
+// keeps no backups. It must be called while the datasource lock is held.
+func (c *Client) autoBackup() error {
+	if c.backupKeep == 0 {
+		return nil
+	}
+	if _, err := createBackup(c.filename); err != nil {
+		return err
+	}
+	return c.rotateBackups()
+}
+
+// BackupAt returns the most recent snapshot taken at or before t.
+func BackupAt(filename string, t time.Time) (Backup, error) {
+	return New(filename).BackupAt(context.Background(), t)
+}
+
+// BackupAt returns the most recent snapshot taken at or before t.
+func (c *Client) BackupAt(ctx context.Context, t time.Time) (Backup, error) {
+	backups, err := c.Backups(ctx)
+	if err != nil {
+		return Backup{}, err
+	}
+	for _, backup := range slices.Backward(backups) {
+		if !backup.Time.After(t) {
+			return backup, nil
+		}
+	}
+	return Backup{}, fmt.Errorf("at %s: %w", t.Format(time.RFC3339), ErrNoBackup)
+}
+
+func readBackup(backup Backup) ([]Task, error) {
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	tasks, err := decodeTasks(backup.Path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse backup: %w", err)
+	}
+	return tasks, nil
+}
+
+// DiffBackup returns the changes RestoreBackup would make to the current tasks,
+// and the version of the tasks they were compared with.
+func DiffBackup(filename string, backup Backup) ([]Change, string, error) {
+	return New(filename).DiffBackup(context.Background(), backup)
+}
+
+// DiffBackup returns the changes RestoreBackup would make to the current tasks,
+// and the version of the tasks they were compared with. Passing that version
+// to RestoreBackup makes sure no other change is overwritten unseen.
+func (c *Client) DiffBackup(ctx context.Context, backup Backup) ([]Change, string, error) {
+	current, err := c.load(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	restored, err := readBackup(backup)
+	if err != nil {
+		return nil, "", err
+	}
+	keepUUIDs(restored, current)
+	return diffTasks(current, restored), restoreVersion(current), nil
+}
+
+// restoreVersion is the ListVersion of tasks without their UUIDs. Rows from older
+// files only get a UUID on the first write, so leaving them out gives the same
+// version before and after that. A restore keeps the current UUIDs anyway.
+func restoreVersion(tasks []Task) string {
+	tasks = slices.Clone(tasks)
+	for i := range tasks {
+		tasks[i].UUID = ""
+	}
+	return ListVersion(tasks)
+}
+
+// keepUUIDs gives restored tasks the UUID the current task with the same ID has,
+// so restoring a snapshot from before UUIDs existed does not change them.
+func keepUUIDs(restored, current []Task) {
+	uuids := make(map[int]string, len(current))
+	for _, task := range current {
+		uuids[task.ID] = task.UUID
+	}
+	for i, task := range restored {
+		if uuid := uuids[task.ID]; uuid != "" {
+			restored[i].UUID = uuid
+		}
+	}
+}
+
+// RestoreBackup atomically replaces the data file with the snapshot. Unless
+// ifVersion is empty, it only does so while the current tasks still have the
+// version DiffBackup returned; otherwise ErrConflict is returned.
+func RestoreBackup(filename string, backup Backup, ifVersion string) error {
+	return New(filename).RestoreBackup(context.Background(), backup, ifVersion)
+}
+
+// RestoreBackup atomically replaces the data file with the snapshot. Unless
+// ifVersion is empty, it only does so while the current tasks still have the
+// version DiffBackup returned; otherwise ErrConflict is returned. Tasks keep their
+// current UUID. The current state is snapshotted first and the restore is recorded
+// in the journal, so it can itself be undone.
+func (c *Client) RestoreBackup(ctx context.Context, backup Backup, ifVersion string) error {
+	restored, err := readBackup(backup)
+	if err != nil {
+		return err
+	}
+	return c.update(ctx, "rollback", func(tasks []Task) ([]Task, error) {
+		if ifVersion != "" && restoreVersion(tasks) != ifVersion {
+			return nil, ErrConflict
+		}
+		keepUUIDs(restored, tasks)
+		assignMissingUUIDs(restored)
+		return restored, nil
+	})
+}
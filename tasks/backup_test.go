@@ -0,0 +1,216 @@
+package tasks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func withBackupKeep(t *testing.T, keep int) {
+	t.Helper()
+	orig := BackupKeep
+	BackupKeep = keep
+	t.Cleanup(func() { BackupKeep = orig })
+}
+
+func TestAutomaticBackups(t *testing.T) {
+	withBackupKeep(t, 2)
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	for _, desc := range []string{"One", "Two", "Three"} {
+		if err := AddTask(tmpFile, desc); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+
+	for _, id := range []string{"1", "2", "3"} {
+		if err := CompleteTask(tmpFile, id); err != nil {
+			t.Fatalf("CompleteTask error: %v", err)
+		}
+	}
+
+	backups, err := ListBackups(tmpFile)
+	if err != nil {
+		t.Fatalf("ListBackups error: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected rotation to keep 2 backups, got %d", len(backups))
+	}
+	if !backups[0].Time.Before(backups[1].Time) {
+		t.Errorf("expected backups oldest first, got %v", backups)
+	}
+	tasks, err := readBackup(backups[1])
+	if err != nil {
+		t.Fatalf("readBackup error: %v", err)
+	}
+	if !tasks[0].IsCompleted || !tasks[1].IsCompleted || tasks[2].IsCompleted {
+		t.Errorf("expected the latest backup to hold the state before completing task 3, got %+v", tasks)
+	}
+}
+
+func TestAutomaticBackupsDisabled(t *testing.T) {
+	withBackupKeep(t, 0)
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := AddTask(tmpFile, "One"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	if err := CompleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	if _, err := os.Stat(backupDir(tmpFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no backup directory, got %v", err)
+	}
+}
+
+func TestBackupMaxAge(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := AddTask(tmpFile, "One"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	stale := filepath.Join(backupDir(tmpFile), time.Now().Add(-48*time.Hour).UTC().Format(backupTimeFormat)+".csv")
+	if err := os.MkdirAll(backupDir(tmpFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte(csvHeader), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := BackupMaxAge
+	BackupMaxAge = 24 * time.Hour
+	t.Cleanup(func() { BackupMaxAge = orig })
+	if _, err := CreateBackup(tmpFile); err != nil {
+		t.Fatalf("CreateBackup error: %v", err)
+	}
+	backups, err := ListBackups(tmpFile)
+	if err != nil {
+		t.Fatalf("ListBackups error: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Path == stale {
+		t.Errorf("expected only the new backup to remain, got %v", backups)
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	for _, desc := range []string{"One", "Two"} {
+		if err := AddTask(tmpFile, desc); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	if _, err := CreateBackup(tmpFile); err != nil {
+		t.Fatalf("CreateBackup error: %v", err)
+	}
+	at := time.Now()
+	if err := DeleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("DeleteTask error: %v", err)
+	}
+	if err := AddTask(tmpFile, "Three"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+
+	t.Run("no backup before the first one", func(t *testing.T) {
+		if _, err := BackupAt(tmpFile, at.Add(-time.Hour)); !errors.Is(err, ErrNoBackup) {
+			t.Errorf("expected ErrNoBackup, got %v", err)
+		}
+	})
+
+	backup, err := BackupAt(tmpFile, at)
+	if err != nil {
+		t.Fatalf("BackupAt error: %v", err)
+	}
+
+	t.Run("diff shows what the restore changes", func(t *testing.T) {
+		changes, _, err := DiffBackup(tmpFile, backup)
+		if err != nil {
+			t.Fatalf("DiffBackup error: %v", err)
+		}
+		if len(changes) != 2 {
+			t.Fatalf("expected 2 changes, got %+v", changes)
+		}
+		if changes[0].ID != 1 || len(changes[0].Fields()) != 1 || changes[0].Fields()[0].Field != "DeletedAt" {
+			t.Errorf("expected task 1 to be undeleted, got %+v", changes[0])
+		}
+		if changes[1].ID != 3 || changes[1].After != nil {
+			t.Errorf("expected task 3 to be removed, got %+v", changes[1])
+		}
+	})
+
+	t.Run("restore replaces the tasks and can be undone", func(t *testing.T) {
+		if err := RestoreBackup(tmpFile, backup, ""); err != nil {
+			t.Fatalf("RestoreBackup error: %v", err)
+		}
+		tasks := readAllTasks(t, tmpFile)
+		if got := taskIDs(tasks); len(got) != 2 || tasks[0].IsDeleted() {
+			t.Errorf("expected tasks 1 and 2 restored, got %+v", tasks)
+		}
+
+		if _, err := Undo(tmpFile); err != nil {
+			t.Fatalf("Undo error: %v", err)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 3 {
+			t.Errorf("expected undo to bring back all 3 tasks, got %v", got)
+		}
+	})
+
+	t.Run("restore fails when the tasks changed after the diff", func(t *testing.T) {
+		_, version, err := DiffBackup(tmpFile, backup)
+		if err != nil {
+			t.Fatalf("DiffBackup error: %v", err)
+		}
+		if err := AddTask(tmpFile, "Four"); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+		if err := RestoreBackup(tmpFile, backup, version); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 4 {
+			t.Errorf("expected the tasks to be left alone, got %v", got)
+		}
+	})
+}
+
+func TestRestoreBackupWithoutUUIDs(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	legacy := "ID,Description,CreatedAt,IsComplete\n" +
+		"1,One,2025-05-12T10:00:00Z,false\n" +
+		"2,Two,2025-05-12T10:00:00Z,false\n"
+	if err := os.WriteFile(tmpFile, []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	backup, err := CreateBackup(tmpFile)
+	if err != nil {
+		t.Fatalf("CreateBackup error: %v", err)
+	}
+	if err := os.WriteFile(tmpFile, []byte(legacy+"3,Three,2025-05-12T10:00:00Z,false\n"), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	t.Run("version from the diff matches rows without a UUID", func(t *testing.T) {
+		_, version, err := DiffBackup(tmpFile, backup)
+		if err != nil {
+			t.Fatalf("DiffBackup error: %v", err)
+		}
+		if err := RestoreBackup(tmpFile, backup, version); err != nil {
+			t.Fatalf("RestoreBackup error: %v", err)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 2 {
+			t.Errorf("expected tasks 1 and 2 restored, got %v", got)
+		}
+	})
+
+	t.Run("restored rows keep the current UUIDs", func(t *testing.T) {
+		current := readAllTasks(t, tmpFile)
+		if current[0].UUID == "" {
+			t.Fatalf("expected UUIDs to be assigned, got %+v", current)
+		}
+		if err := RestoreBackup(tmpFile, backup, ""); err != nil {
+			t.Fatalf("RestoreBackup error: %v", err)
+		}
+		for i, task := range readAllTasks(t, tmpFile) {
+			if task.UUID != current[i].UUID {
+				t.Errorf("task %d UUID = %q, want %q", task.ID, task.UUID, current[i].UUID)
+			}
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+)
+
+// Client reads and changes the tasks stored in one data file. Its methods take a
+// context, which bounds how long they wait for another process to release the
+// data file, and report every failure through their returned error.
+//
+// The package-level functions are shorthands that use a Client with the default
+// settings and context.Background.
+type Client struct {
+	filename     string
+	lockTimeout  time.Duration
+	backupKeep   int
+	backupMaxAge time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// New returns a Client for the data file at filename. Without options it uses the
+// current values of LockTimeout, BackupKeep and BackupMaxAge.
+func New(filename string, opts ...Option) *Client {
+	c := &Client{
+		filename:     filename,
+		lockTimeout:  LockTimeout,
+		backupKeep:   BackupKeep,
+		backupMaxAge: BackupMaxAge,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithLockTimeout bounds how long the client waits for another process to release
+// the data file, like LockTimeout. Zero waits until the context is done and
+// NoWait does not wait at all.
+func WithLockTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.lockTimeout = d
+	}
+}
+
+// WithBackups keeps the given number of snapshots of the data file, none older than
+// maxAge, like BackupKeep and BackupMaxAge. A keep of zero disables automatic backups.
+func WithBackups(keep int, maxAge time.Duration) Option {
+	return func(c *Client) {
+		c.backupKeep = keep
+		c.backupMaxAge = maxAge
+	}
+}
+
+// Filename returns the data file of the client.
+func (c *Client) Filename() string {
+	return c.filename
+}
+
+// closeLocked closes file and reports a failure through err, unless err already
+// holds an earlier error. It is meant to be deferred with a named error result.
+func closeLocked(file *lockedFile, err *error) {
+	if closeErr := closeFile(file); closeErr != nil && *err == nil {
+		*err = fmt.Errorf("failed to close file: %w", closeErr)
+	}
+}
@@ -0,0 +1,121 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	c := New(filepath.Join(t.TempDir(), "tasks.csv"))
+
+	task, err := c.Add(ctx, "Task1")
+	if err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	if task.ID != 1 || task.UUID == "" || task.Description != "Task1" {
+		t.Errorf("Add() = %+v", task)
+	}
+	if _, err := c.Add(ctx, "Task2"); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+
+	if err := c.Complete(ctx, 1); err != nil {
+		t.Fatalf("Complete error: %v", err)
+	}
+	if err := c.Delete(ctx, 2); err != nil {
+		t.Fatalf("Delete error: %v", err)
+	}
+	list, err := c.List(ctx, true)
+	if err != nil {
+		t.Fatalf("List error: %v", err)
+	}
+	if got := taskIDs(list); len(got) != 1 || !list[0].IsCompleted {
+		t.Errorf("List() = %+v, want completed task 1", list)
+	}
+
+	byUUID, err := c.GetByUUID(ctx, task.UUID)
+	if err != nil || byUUID.ID != 1 {
+		t.Errorf("GetByUUID() = %+v, %v", byUUID, err)
+	}
+	if _, err := c.Get(ctx, 99); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Get(99) error = %v, want ErrTaskNotFound", err)
+	}
+	if err := c.Restore(ctx, 1); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Restore(1) error = %v, want ErrTaskNotFound", err)
+	}
+
+	if _, err := c.Undo(ctx); err != nil {
+		t.Fatalf("Undo error: %v", err)
+	}
+	if trash, err := c.Trash(ctx); err != nil || len(trash) != 0 {
+		t.Errorf("Trash() after undo = %+v, %v", trash, err)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	c := New("tasks.csv", WithLockTimeout(NoWait), WithBackups(3, time.Hour))
+	if c.Filename() != "tasks.csv" || c.lockTimeout != NoWait || c.backupKeep != 3 || c.backupMaxAge != time.Hour {
+		t.Errorf("New() = %+v", c)
+	}
+
+	withLockTimeout(t, time.Second)
+	if c := New("tasks.csv"); c.lockTimeout != time.Second || c.backupKeep != BackupKeep {
+		t.Errorf("expected the package defaults, got %+v", c)
+	}
+}
+
+func TestClientBackupOption(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	c := New(tmpFile, WithBackups(0, 0))
+	ctx := context.Background()
+	if _, err := c.Add(ctx, "Task1"); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	if err := c.Complete(ctx, 1); err != nil {
+		t.Fatalf("Complete error: %v", err)
+	}
+	if _, err := os.Stat(backupDir(tmpFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no backups, got %v", err)
+	}
+}
+
+func TestClientContextWhileLocked(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	holdLock(t, tmpFile)
+	c := New(tmpFile)
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := c.List(ctx, true)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+			t.Errorf("expected to wait for the context, returned after %v", elapsed)
+		}
+	})
+
+	t.Run("cancelled context fails before waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := c.Add(ctx, "Task"); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("lock timeout still applies", func(t *testing.T) {
+		c := New(tmpFile, WithLockTimeout(50*time.Millisecond))
+		err := c.Complete(context.Background(), 1)
+		var locked *ErrLocked
+		if !errors.As(err, &locked) {
+			t.Errorf("expected ErrLocked, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSharedLockAllowsConcurrentReaders(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	reader, err := New(tmpFile).open(context.Background(), false)
+	if err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+	defer closeFile(reader)
+
+	withLockTimeout(t, NoWait)
+	if _, err := ListTasks(tmpFile, true); err != nil {
+		t.Errorf("expected a second reader not to wait, got %v", err)
+	}
+	var locked *ErrLocked
+	if err := AddTask(tmpFile, "Task"); !errors.As(err, &locked) {
+		t.Errorf("expected a writer to be blocked by the reader, got %v", err)
+	}
+
+	info, err := LockStatus(tmpFile)
+	if err != nil || !info.Locked || !info.Shared {
+		t.Errorf("LockStatus() = %+v, %v; want shared lock", info, err)
+	}
+}
+
+func TestConcurrentReadersAndWriters(t *testing.T) {
+	const (
+		writers        = 8
+		tasksPerWriter = 10
+		readers        = 8
+	)
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*tasksPerWriter*2+readers)
+	done := make(chan struct{})
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < tasksPerWriter; i++ {
+				description := fmt.Sprintf("writer %d task %d", w, i)
+				if err := AddTask(tmpFile, description); err != nil {
+					errs <- fmt.Errorf("AddTask: %w", err)
+					return
+				}
+				// Complete it while other writers keep rewriting the file around it
+				tasks, err := ListTasks(tmpFile, false)
+				if err != nil {
+					errs <- fmt.Errorf("ListTasks: %w", err)
+					return
+				}
+				for _, task := range tasks {
+					if task.Description == description {
+						if err := CompleteTask(tmpFile, task.UUID); err != nil {
+							errs <- fmt.Errorf("CompleteTask: %w", err)
+							return
+						}
+					}
+				}
+			}
+		}()
+	}
+
+	var readersWG sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		readersWG.Add(1)
+		go func() {
+			defer readersWG.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if _, err := ListTasks(tmpFile, true); err != nil {
+					errs <- fmt.Errorf("ListTasks: %w", err)
+					return
+				}
+				// flock does not queue writers behind readers, so leave them a gap
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+	readersWG.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	tasks := readAllTasks(t, tmpFile)
+	if len(tasks) != writers*tasksPerWriter {
+		t.Fatalf("expected %d tasks, got %d", writers*tasksPerWriter, len(tasks))
+	}
+	seen := make(map[int]bool)
+	for _, task := range tasks {
+		if seen[task.ID] {
+			t.Errorf("duplicate task ID %d", task.ID)
+		}
+		seen[task.ID] = true
+		if !task.IsCompleted {
+			t.Errorf("lost update: task %d is not completed", task.ID)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Problem is a defect found in the data file by Diagnose.
+type Problem struct {
+	// Line is the line of the data file the problem starts on.
+	Line int
+	// Reason describes what is wrong.
+	Reason string
+	// Raw is the exact text of the offending row, or empty when there is none.
+	Raw string
+	// Info is set when nothing needs repairing, as for a column tasker does not know.
+	Info bool
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Reason)
+}
+
+func rejectsPath(filename string) string {
+	return filename + ".rejects"
+}
+
+// diagnoseCSVData parses data like readTasksFromCSVData but, instead of failing on
+// the first bad row, returns every good task along with a problem for each bad row.
+func diagnoseCSVData(data []byte) ([]Task, []Problem) {
+	if len(data) == 0 {
+		return nil, []Problem{{Line: 1, Reason: "missing header"}}
+	}
+
+	var problems []Problem
+	csvReader := newCSVReader(bytes.NewReader(data))
+
+	// A wrong header is quarantined as well, in case it is actually a task, and the
+	// rows are read assuming the current column order
+	header, err := csvReader.Read()
+	offset := csvReader.InputOffset()
+	cols := defaultColumns
+	switch {
+	case err == io.EOF:
+		problems = append(problems, Problem{Line: 1, Reason: "missing header"})
+	case err != nil:
+		problems = append(problems, Problem{Line: 1, Reason: fmt.Sprintf("unreadable header: %v", err), Raw: string(data[:offset])})
+	default:
+		line, _ := csvReader.FieldPos(0)
+		if cols, err = parseHeader(header); err != nil {
+			problems = append(problems, Problem{Line: line, Reason: fmt.Sprintf("unexpected header: %v", err), Raw: string(data[:offset])})
+			cols = defaultColumns
+			break
+		}
+		for _, name := range header {
+			if !slices.Contains(csvColumns, name) {
+				problems = append(problems, Problem{Line: line, Reason: fmt.Sprintf("unknown column %s is kept as it is", name), Info: true})
+			}
+		}
+	}
+
+	var tasks []Task
+	firstLine := make(map[int]int)
+	firstUUIDLine := make(map[string]int)
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		raw := string(data[offset:csvReader.InputOffset()])
+		offset = csvReader.InputOffset()
+
+		var line int
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			line = parseErr.StartLine
+		} else {
+			line, _ = csvReader.FieldPos(0)
+		}
+		reject := func(reason string) {
+			problems = append(problems, Problem{Line: line, Reason: reason, Raw: raw})
+		}
+
+		if err != nil {
+			reject(err.Error())
+			continue
+		}
+		task, err := parseRecord(cols, record)
+		if err != nil {
+			reject(err.Error())
+			continue
+		}
+		if first, ok := firstLine[task.ID]; ok {
+			reject(fmt.Sprintf("duplicate ID %d (first seen on line %d)", task.ID, first))
+			continue
+		}
+		if first, ok := firstUUIDLine[task.UUID]; ok && task.UUID != "" {
+			reject(fmt.Sprintf("duplicate UUID %s (first seen on line %d)", task.UUID, first))
+			continue
+		}
+		firstLine[task.ID] = line
+		firstUUIDLine[task.UUID] = line
+		tasks = append(tasks, task)
+	}
+	return tasks, problems
+}
+
+// Diagnose scans the data file and reports duplicate IDs, unparsable fields, short
+// rows and a wrong header, with the line each problem was found on.
+func Diagnose(filename string) ([]Problem, error) {
+	return New(filename).Diagnose(context.Background())
+}
+
+// Diagnose scans the data file and reports duplicate IDs, unparsable fields, short
+// rows and a wrong header, with the line each problem was found on. Columns tasker
+// does not know are reported as well, marked as Info.
+func (c *Client) Diagnose(ctx context.Context) (_ []Problem, err error) {
+	if isEventLog(c.filename) {
+		return nil, fmt.Errorf("%s is an event log: only CSV data files can be checked", c.filename)
+	}
+	file, err := c.open(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer closeLocked(file, &err)
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	_, problems := diagnoseCSVData(data)
+	return problems, nil
+}
+
+// Repair fixes the problems reported by Diagnose and returns them.
+func Repair(filename string) ([]Problem, error) {
+	return New(filename).Repair(context.Background())
+}
+
+// Repair fixes the problems reported by Diagnose: bad rows are appended to
+// <file>.rejects together with the reason, and the data file is rewritten with a
+// correct header and every good task. It returns the problems that were fixed,
+// leaving out those marked as Info.
+func (c *Client) Repair(ctx context.Context) (_ []Problem, err error) {
+	if isEventLog(c.filename) {
+		return nil, fmt.Errorf("%s is an event log: only CSV data files can be repaired", c.filename)
+	}
+	file, err := c.open(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open datasource for updating: %w", err)
+	}
+	defer closeLocked(file, &err)
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	tasks, problems := diagnoseCSVData(data)
+	problems = slices.DeleteFunc(problems, func(p Problem) bool { return p.Info })
+	if len(problems) == 0 {
+		return nil, nil
+	}
+
+	// Quarantine first, so rows are never lost if rewriting the data file fails
+	var rejects strings.Builder
+	for _, problem := range problems {
+		if problem.Raw == "" {
+			continue
+		}
+		fmt.Fprintf(&rejects, "# %s: %s\n%s", c.filename, problem, problem.Raw)
+		if !strings.HasSuffix(problem.Raw, "\n") {
+			rejects.WriteString("\n")
+		}
+	}
+	if rejects.Len() > 0 {
+		f, err := os.OpenFile(rejectsPath(c.filename), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open rejects file: %w", err)
+		}
+		_, err = f.WriteString(rejects.String())
+		if err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write rejects file: %w", err)
+		}
+	}
+
+	if err := replaceTasks(file, tasks); err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
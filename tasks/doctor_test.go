@@ -0,0 +1,128 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseCSVData(t *testing.T) {
+	cases := []struct {
+		name      string
+		data      string
+		wantTasks []int
+		wantLines []int
+		wantErr   string
+	}{
+		{
+			name:      "Valid file",
+			data:      csvHeader + "1,Task1,2025-05-12T10:00:00Z,false\n2,Task2,2025-05-12T11:00:00Z,true\n",
+			wantTasks: []int{1, 2},
+		},
+		{
+			name:      "Empty file",
+			data:      "",
+			wantLines: []int{1},
+			wantErr:   "missing header",
+		},
+		{
+			name:      "Wrong header",
+			data:      "Task,Description,CreatedAt,IsComplete\n1,Task1,2025-05-12T10:00:00Z,false\n",
+			wantTasks: []int{1},
+			wantLines: []int{1},
+			wantErr:   "missing column ID",
+		},
+		{
+			name:      "Short row",
+			data:      csvHeader + "1,Task1,2025-05-12T10:00:00Z,false\n2,Task2\n",
+			wantTasks: []int{1},
+			wantLines: []int{4},
+			wantErr:   "wrong number of fields",
+		},
+		{
+			name:      "Bad timestamp and bool",
+			data:      csvHeader + "1,Task1,yesterday,false\n2,Task2,2025-05-12T11:00:00Z,maybe\n3,Task3,2025-05-12T11:00:00Z,false\n",
+			wantTasks: []int{3},
+			wantLines: []int{3, 4},
+			wantErr:   "failed to parse",
+		},
+		{
+			name:      "Duplicate ID",
+			data:      csvHeader + "1,Task1,2025-05-12T10:00:00Z,false\n1,Again,2025-05-12T11:00:00Z,false\n",
+			wantTasks: []int{1},
+			wantLines: []int{4},
+			wantErr:   "duplicate ID 1 (first seen on line 3)",
+		},
+		{
+			name:      "Bare quote",
+			data:      csvHeader + "1,Ta\"sk1,2025-05-12T10:00:00Z,false\n2,Task2,2025-05-12T11:00:00Z,false\n",
+			wantTasks: []int{2},
+			wantLines: []int{3},
+			wantErr:   "bare \"",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tasks, problems := diagnoseCSVData([]byte(tc.data))
+			if got := taskIDs(tasks); len(got) != len(tc.wantTasks) || (len(got) > 0 && got[0] != tc.wantTasks[0]) {
+				t.Errorf("good tasks = %v, want %v", got, tc.wantTasks)
+			}
+			if len(problems) != len(tc.wantLines) {
+				t.Fatalf("problems = %v, want lines %v", problems, tc.wantLines)
+			}
+			for i, problem := range problems {
+				if problem.Line != tc.wantLines[i] {
+					t.Errorf("problem %d on line %d, want %d", i, problem.Line, tc.wantLines[i])
+				}
+				if !strings.Contains(problem.Reason, tc.wantErr) {
+					t.Errorf("problem %d reason %q, want it to contain %q", i, problem.Reason, tc.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	content := csvHeader +
+		"1,Task1,2025-05-12T10:00:00Z,false\n" +
+		"2,Broken,notatime,false\n" +
+		"3,Task3,2025-05-12T11:00:00Z,true\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	if _, err := ListTasks(tmpFile, true); err == nil {
+		t.Fatalf("expected the broken row to make ListTasks fail")
+	}
+
+	fixed, err := Repair(tmpFile)
+	if err != nil {
+		t.Fatalf("Repair error: %v", err)
+	}
+	if len(fixed) != 1 || fixed[0].Line != 4 {
+		t.Errorf("expected line 4 to be fixed, got %v", fixed)
+	}
+
+	tasks, err := ListTasks(tmpFile, true)
+	if err != nil {
+		t.Fatalf("ListTasks error after repair: %v", err)
+	}
+	if got := taskIDs(tasks); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Errorf("expected good tasks 1 and 3 to be kept, got %v", got)
+	}
+
+	rejects, err := os.ReadFile(rejectsPath(tmpFile))
+	if err != nil {
+		t.Fatalf("failed to read rejects: %v", err)
+	}
+	if !strings.Contains(string(rejects), "2,Broken,notatime,false\n") || !strings.Contains(string(rejects), "line 4") {
+		t.Errorf("expected the bad row to be quarantined with its line, got %q", rejects)
+	}
+
+	problems, err := Diagnose(tmpFile)
+	if err != nil || len(problems) != 0 {
+		t.Errorf("Diagnose() after repair = %v, %v; want no problems", problems, err)
+	}
+}
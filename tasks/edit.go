@@ -0,0 +1,113 @@
+package tasks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrConflict is returned when a task no longer has the version a change was based on.
+var ErrConflict = errors.New("task was changed since it was read")
+
+// TaskPatch lists the fields of a task to change. Nil fields are left alone.
+type TaskPatch struct {
+	Description *string `json:"description,omitempty"`
+	IsCompleted *bool   `json:"is_completed,omitempty"`
+	// IsDeleted moves the task to the trash, or restores it from there.
+	IsDeleted *bool `json:"is_deleted,omitempty"`
+}
+
+// op names the change in the journal and history after the only kind of field it
+// sets, or "edit" when it sets several.
+func (p TaskPatch) op() string {
+	switch {
+	case p.Description == nil && p.IsDeleted == nil && p.IsCompleted != nil:
+		if *p.IsCompleted {
+			return "complete"
+		}
+		return "reopen"
+	case p.Description == nil && p.IsCompleted == nil && p.IsDeleted != nil:
+		if *p.IsDeleted {
+			return "delete"
+		}
+		return "restore"
+	}
+	return "edit"
+}
+
+// apply changes task as the patch says, stamping completion and deletion with now.
+func (p TaskPatch) apply(task *Task, now time.Time) {
+	if p.Description != nil {
+		task.Description = *p.Description
+	}
+	if p.IsCompleted != nil && *p.IsCompleted != task.IsCompleted {
+		task.IsCompleted = *p.IsCompleted
+		task.CompletedAt = time.Time{}
+		if task.IsCompleted {
+			task.CompletedAt = now
+		}
+	}
+	if p.IsDeleted != nil && *p.IsDeleted != task.IsDeleted() {
+		task.DeletedAt = time.Time{}
+		if *p.IsDeleted {
+			task.DeletedAt = now
+		}
+	}
+}
+
+// Version identifies the current state of the task. It changes whenever any field
+// does, so it can serve as an ETag.
+func (t Task) Version() string {
+	data, _ := json.Marshal(t)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ListVersion identifies the state of a list of tasks, like Version does for one.
+func ListVersion(tasks []Task) string {
+	hash := sha256.New()
+	for _, task := range tasks {
+		fmt.Fprintln(hash, task.Version())
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+// EditTask changes the description of the task with the given ID or UUID.
+func EditTask(filename string, taskID string, description string) error {
+	ref, err := parseTaskRef(taskID)
+	if err != nil {
+		return err
+	}
+	_, err = New(filename).edit(context.Background(), ref, TaskPatch{Description: &description}, "")
+	return err
+}
+
+// Edit applies patch to the task with the given ID, including tasks in the trash,
+// and returns the result. Unless ifVersion is empty, the task is only changed while
+// its Version still equals ifVersion; otherwise ErrConflict is returned.
+func (c *Client) Edit(ctx context.Context, id int, patch TaskPatch, ifVersion string) (Task, error) {
+	return c.edit(ctx, taskRef{id: id}, patch, ifVersion)
+}
+
+func (c *Client) edit(ctx context.Context, ref taskRef, patch TaskPatch, ifVersion string) (Task, error) {
+	var edited Task
+	err := c.update(ctx, patch.op(), func(tasks []Task) ([]Task, error) {
+		for i, task := range tasks {
+			if !ref.matches(task) {
+				continue
+			}
+			if ifVersion != "" && task.Version() != ifVersion {
+				return nil, fmt.Errorf("task %s: %w", ref, ErrConflict)
+			}
+			patch.apply(&tasks[i], time.Now().Truncate(time.Second))
+			edited = tasks[i]
+			return tasks, nil
+		}
+		return nil, fmt.Errorf("task %s: %w", ref, ErrTaskNotFound)
+	})
+	return edited, err
+}
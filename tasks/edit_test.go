@@ -0,0 +1,73 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditTask(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := AddTask(tmpFile, "Typo"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+
+	if err := EditTask(tmpFile, "1", "Fixed"); err != nil {
+		t.Fatalf("EditTask error: %v", err)
+	}
+	if tasks := readAllTasks(t, tmpFile); tasks[0].Description != "Fixed" {
+		t.Errorf("expected the description to change, got %+v", tasks[0])
+	}
+	if err := EditTask(tmpFile, "2", "Missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("EditTask(2) error = %v, want ErrTaskNotFound", err)
+	}
+
+	entries, err := UndoList(tmpFile)
+	if err != nil || len(entries) == 0 || entries[0].Op != "edit" {
+		t.Errorf("expected the edit in the journal, got %+v, %v", entries, err)
+	}
+}
+
+func TestClientEdit(t *testing.T) {
+	ctx := context.Background()
+	c := New(filepath.Join(t.TempDir(), "tasks.csv"))
+	task, err := c.Add(ctx, "Task1")
+	if err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	yes, desc := true, "Renamed"
+
+	t.Run("patch sets completion and description", func(t *testing.T) {
+		edited, err := c.Edit(ctx, 1, TaskPatch{Description: &desc, IsCompleted: &yes}, task.Version())
+		if err != nil {
+			t.Fatalf("Edit error: %v", err)
+		}
+		if edited.Description != desc || !edited.IsCompleted || edited.CompletedAt.IsZero() {
+			t.Errorf("Edit() = %+v", edited)
+		}
+		if edited.Version() == task.Version() {
+			t.Errorf("expected the version to change")
+		}
+	})
+
+	t.Run("stale version conflicts", func(t *testing.T) {
+		_, err := c.Edit(ctx, 1, TaskPatch{IsDeleted: &yes}, task.Version())
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("Edit error = %v, want ErrConflict", err)
+		}
+		if got, _ := c.Get(ctx, 1); got.IsDeleted() {
+			t.Errorf("expected the task to be left alone, got %+v", got)
+		}
+	})
+
+	t.Run("op names a single kind of change", func(t *testing.T) {
+		if _, err := c.Edit(ctx, 1, TaskPatch{IsDeleted: &yes}, ""); err != nil {
+			t.Fatalf("Edit error: %v", err)
+		}
+		entries, err := c.UndoList(ctx)
+		if err != nil || entries[0].Op != "delete" || entries[1].Op != "edit" {
+			t.Errorf("UndoList() = %+v, %v", entries, err)
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// snapshotInterval is how many events may follow the last snapshot of an event log
+// before the next change appends a new one, bounding the work of replaying it.
+var snapshotInterval = 1000
+
+// ErrNotEventLog is returned by operations that only apply to event logs.
+var ErrNotEventLog = errors.New("not an event log")
+
+// logEvent is one line of an event log. Every event except removed carries the
+// state of the task after it; a snapshot carries every task.
+type logEvent struct {
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	ID    int       `json:"id,omitempty"`
+	Task  *Task     `json:"task,omitempty"`
+	Tasks []Task    `json:"tasks,omitempty"`
+}
+
+// Event types of an event log.
+const (
+	eventCreated   = "created"
+	eventEdited    = "edited"
+	eventCompleted = "completed"
+	eventReopened  = "reopened"
+	eventDeleted   = "deleted"
+	eventRestored  = "restored"
+	eventRemoved   = "removed"
+	eventSnapshot  = "snapshot"
+)
+
+// snapshotPrefix starts every snapshot line, so snapshots can be found without
+// decoding the events before them.
+var snapshotPrefix = []byte(`{"type":"` + eventSnapshot + `"`)
+
+// isEventLog reports whether the data file at path is an append-only event log
+// rather than a CSV file. The mode is chosen by the .jsonl extension.
+func isEventLog(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".jsonl")
+}
+
+// decodeTasks parses the full contents of a data file, its archive or a backup in
+// the format selected by path.
+func decodeTasks(path string, data []byte) ([]Task, error) {
+	if isEventLog(path) {
+		return replayEventLog(data)
+	}
+	return readTasksFromCSVData(data)
+}
+
+// encodeTasks writes tasks as the full contents of a new file at path, in the format
+// selected by path. An event log is written as a single snapshot.
+func encodeTasks(path string, f *os.File, tasks []Task) error {
+	if isEventLog(path) {
+		return writeEvents(f, []logEvent{snapshotEvent(tasks)})
+	}
+	return writeTasksToFile(f, tasks)
+}
+
+// saveChanges stores changes in the locked data file, whose contents before the
+// changes were data. Event logs get the changes appended as events, and a snapshot
+// of tasks once enough events have accumulated; CSV files are rewritten with tasks.
+func saveChanges(file *lockedFile, data []byte, tasks []Task, changes []Change) error {
+	if !isEventLog(file.Name()) {
+		return replaceTasks(file, tasks)
+	}
+
+	events := changeEvents(changes)
+	if _, pending := lastSnapshot(data); pending+len(events) >= snapshotInterval {
+		events = append(events, snapshotEvent(tasks))
+	}
+	return appendEvents(file, events)
+}
+
+func snapshotEvent(tasks []Task) logEvent {
+	if tasks == nil {
+		tasks = []Task{}
+	}
+	return logEvent{Type: eventSnapshot, Time: time.Now(), Tasks: tasks}
+}
+
+// changeEvents converts changes into the events that record them.
+func changeEvents(changes []Change) []logEvent {
+	now := time.Now()
+	events := make([]logEvent, 0, len(changes))
+	for _, change := range changes {
+		event := logEvent{Type: eventType(change), Time: now, ID: change.ID, Task: change.After}
+		events = append(events, event)
+	}
+	return events
+}
+
+// eventType names what a change did to its task.
+func eventType(change Change) string {
+	before, after := change.Before, change.After
+	switch {
+	case before == nil:
+		return eventCreated
+	case after == nil:
+		return eventRemoved
+	case !before.IsDeleted() && after.IsDeleted():
+		return eventDeleted
+	case before.IsDeleted() && !after.IsDeleted():
+		return eventRestored
+	case !before.IsCompleted && after.IsCompleted:
+		return eventCompleted
+	case before.IsCompleted && !after.IsCompleted:
+		return eventReopened
+	default:
+		return eventEdited
+	}
+}
+
+// lastSnapshot returns the offset of the last snapshot in an event log, and how
+// many events follow it.
+func lastSnapshot(data []byte) (offset, pending int) {
+	pos := 0
+	for line := range bytes.Lines(data) {
+		if bytes.HasPrefix(line, snapshotPrefix) {
+			offset, pending = pos, -1
+		}
+		pos += len(line)
+		pending++
+	}
+	return offset, pending
+}
+
+// replayEventLog derives the current tasks from an event log, starting at its last
+// snapshot. A final line without a newline is the remainder of an interrupted write
+// and is ignored.
+func replayEventLog(data []byte) ([]Task, error) {
+	offset, _ := lastSnapshot(data)
+	lineNo := bytes.Count(data[:offset], []byte("\n"))
+
+	state := make(map[int]Task)
+	for line := range bytes.Lines(data[offset:]) {
+		lineNo++
+		var event logEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			if !bytes.HasSuffix(line, []byte("\n")) {
+				break
+			}
+			return nil, fmt.Errorf("line %d: invalid event: %w", lineNo, err)
+		}
+
+		switch event.Type {
+		case eventSnapshot:
+			clear(state)
+			for _, task := range event.Tasks {
+				state[task.ID] = task
+			}
+		case eventRemoved:
+			delete(state, event.ID)
+		default:
+			if event.Task == nil {
+				return nil, fmt.Errorf("line %d: %s event without a task", lineNo, event.Type)
+			}
+			state[event.Task.ID] = *event.Task
+		}
+	}
+
+	tasks := make([]Task, 0, len(state))
+	for _, id := range slices.Sorted(maps.Keys(state)) {
+		tasks = append(tasks, state[id])
+	}
+	return tasks, nil
+}
+
+// writeEvents writes one JSON line per event.
+func writeEvents(w io.Writer, events []logEvent) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write events: %w", err)
+	}
+	return nil
+}
+
+// appendEvents appends events to the locked event log and syncs it. The remainder
+// of an interrupted write is cut off first, so it never ends up before new events.
+func appendEvents(file *lockedFile, events []logEvent) error {
+	end, err := completeLength(file.File)
+	if err != nil {
+		return err
+	}
+	if err := file.Truncate(end); err != nil {
+		return fmt.Errorf("failed to truncate file: %w", err)
+	}
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to end of file: %w", err)
+	}
+	if err := writeEvents(file, events); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	return nil
+}
+
+// completeLength returns the length of the file up to and including its last newline.
+func completeLength(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+	buf := make([]byte, 4096)
+	for end := info.Size(); end > 0; {
+		start := max(0, end-int64(len(buf)))
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return 0, fmt.Errorf("failed to read file: %w", err)
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// Compact folds the event log of filename into a single snapshot of the current
+// tasks, and returns how many lines it replaced.
+func Compact(filename string) (int, error) {
+	return New(filename).Compact(context.Background())
+}
+
+// Compact folds the event log into a single snapshot of the current tasks, and
+// returns how many lines it replaced. The previous log is kept as a backup, even
+// when the client keeps no automatic backups.
+func (c *Client) Compact(ctx context.Context) (_ int, err error) {
+	if !isEventLog(c.filename) {
+		return 0, fmt.Errorf("%s: %w (use a .jsonl file)", c.filename, ErrNotEventLog)
+	}
+	file, err := c.open(ctx, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open datasource for compacting: %w", err)
+	}
+	defer closeLocked(file, &err)
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	tasks, err := replayEventLog(data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay event log: %w", err)
+	}
+	if c.backupKeep == 0 {
+		// The history in the log is gone after this, so keep it regardless
+		if _, err := createBackup(c.filename); err != nil {
+			return 0, err
+		}
+	}
+	if err := replaceTasks(file, tasks); err != nil {
+		return 0, err
+	}
+	return bytes.Count(data, []byte("\n")), nil
+}
@@ -0,0 +1,192 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// logTypes returns the type of every event in the event log at path.
+func logTypes(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read event log: %v", err)
+	}
+	var types []string
+	for line := range bytes.Lines(data) {
+		typ, _, _ := strings.Cut(strings.TrimPrefix(string(line), `{"type":"`), `"`)
+		types = append(types, typ)
+	}
+	return types
+}
+
+func TestEventLog(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.jsonl")
+	for _, desc := range []string{"Task1", "Task2", "Task3"} {
+		if err := AddTask(tmpFile, desc); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	if err := CompleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	if err := DeleteTask(tmpFile, "2"); err != nil {
+		t.Fatalf("DeleteTask error: %v", err)
+	}
+	if _, err := Undo(tmpFile); err != nil {
+		t.Fatalf("Undo error: %v", err)
+	}
+	if _, err := PurgeTasks(tmpFile, 0); err != nil {
+		t.Fatalf("PurgeTasks error: %v", err)
+	}
+
+	t.Run("changes are appended as events", func(t *testing.T) {
+		want := []string{"created", "created", "created", "completed", "deleted", "restored"}
+		if got := logTypes(t, tmpFile); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("event log = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("tasks are derived by replay", func(t *testing.T) {
+		tasks := readAllTasks(t, tmpFile)
+		if got := taskIDs(tasks); len(got) != 3 || !tasks[0].IsCompleted || tasks[1].IsDeleted() {
+			t.Errorf("got %+v", tasks)
+		}
+	})
+
+	t.Run("compact folds the log into a snapshot", func(t *testing.T) {
+		before := readAllTasks(t, tmpFile)
+		folded, err := Compact(tmpFile)
+		if err != nil {
+			t.Fatalf("Compact error: %v", err)
+		}
+		if folded != 6 {
+			t.Errorf("expected 6 events folded, got %d", folded)
+		}
+		if got := logTypes(t, tmpFile); len(got) != 1 || got[0] != "snapshot" {
+			t.Errorf("event log after compact = %v, want one snapshot", got)
+		}
+		after := readAllTasks(t, tmpFile)
+		if len(after) != len(before) || !sameTask(after[0], before[0]) || !sameTask(after[2], before[2]) {
+			t.Errorf("compact changed the tasks: %+v, want %+v", after, before)
+		}
+	})
+
+	t.Run("removed tasks stay removed after compact", func(t *testing.T) {
+		if err := DeleteTask(tmpFile, "3"); err != nil {
+			t.Fatalf("DeleteTask error: %v", err)
+		}
+		if _, err := PurgeTasks(tmpFile, 0); err != nil {
+			t.Fatalf("PurgeTasks error: %v", err)
+		}
+		if got := logTypes(t, tmpFile); strings.Join(got, " ") != "snapshot deleted removed" {
+			t.Errorf("event log = %v", got)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 2 {
+			t.Errorf("expected tasks 1 and 2, got %v", got)
+		}
+	})
+}
+
+func TestEventLogPeriodicSnapshot(t *testing.T) {
+	orig := snapshotInterval
+	snapshotInterval = 3
+	t.Cleanup(func() { snapshotInterval = orig })
+
+	tmpFile := filepath.Join(t.TempDir(), "tasks.jsonl")
+	for _, desc := range []string{"Task1", "Task2"} {
+		if err := AddTask(tmpFile, desc); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	if err := CompleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	if err := CompleteTask(tmpFile, "2"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+
+	want := "created created completed snapshot completed"
+	if got := logTypes(t, tmpFile); strings.Join(got, " ") != want {
+		t.Errorf("event log = %v, want %s", got, want)
+	}
+	tasks := readAllTasks(t, tmpFile)
+	if len(tasks) != 2 || !tasks[0].IsCompleted || !tasks[1].IsCompleted {
+		t.Errorf("got %+v", tasks)
+	}
+}
+
+func TestEventLogInterruptedWrite(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.jsonl")
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	f, err := os.OpenFile(tmpFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString(`{"type":"completed","id":1,"ta`)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := readAllTasks(t, tmpFile)
+	if len(tasks) != 1 || tasks[0].IsCompleted {
+		t.Errorf("expected the partial event to be ignored, got %+v", tasks)
+	}
+	if err := CompleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	if got := logTypes(t, tmpFile); strings.Join(got, " ") != "created completed" {
+		t.Errorf("expected the partial event to be cut off, got %v", got)
+	}
+}
+
+func TestEventLogCorruptLine(t *testing.T) {
+	_, err := replayEventLog([]byte("{\"type\":\"created\",\"task\":{\"id\":1}}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error for line 2, got %v", err)
+	}
+}
+
+func TestCompactBackupsDisabled(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.jsonl")
+	client := New(tmpFile, WithBackups(0, 0))
+	ctx := context.Background()
+	for _, desc := range []string{"Task1", "Task2"} {
+		if _, err := client.Add(ctx, desc); err != nil {
+			t.Fatalf("Add error: %v", err)
+		}
+	}
+	log, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("failed to read event log: %v", err)
+	}
+
+	if _, err := client.Compact(ctx); err != nil {
+		t.Fatalf("Compact error: %v", err)
+	}
+	backups, err := client.Backups(ctx)
+	if err != nil {
+		t.Fatalf("Backups error: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected the old log to be kept as a backup, got %+v", backups)
+	}
+	if data, err := os.ReadFile(backups[0].Path); err != nil || !bytes.Equal(data, log) {
+		t.Errorf("expected the backup to hold the old log, got %q, %v", data, err)
+	}
+}
+
+func TestCompactCSV(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if _, err := Compact(tmpFile); !errors.Is(err, ErrNotEventLog) {
+		t.Errorf("Compact() error = %v, want ErrNotEventLog", err)
+	}
+}
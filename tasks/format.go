@@ -0,0 +1,135 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// formatVersion is the layout of the data file written by this version. It is
+// recorded in a comment line before the header; files without one are version 1.
+// Bump it whenever a column is added, so older versions refuse to rewrite the file
+// and drop the new column.
+const formatVersion = 2
+
+// formatMarker prefixes the comment line that records the format version.
+const formatMarker = "# tasker format "
+
+// csvColumns are the columns written to the data file, in order.
+var csvColumns = []string{"ID", "Description", "CreatedAt", "IsComplete", "DeletedAt", "CompletedAt", "UUID"}
+
+// requiredColumns must be present in the header of every data file; the others
+// were added later and are empty when missing.
+var requiredColumns = csvColumns[:4]
+
+var csvHeader = fmt.Sprintf("%s%d\n%s\n", formatMarker, formatVersion, strings.Join(csvColumns, ","))
+
+// ErrUnsupportedFormat is returned when rewriting a data file written by a newer
+// version, which could hold columns this version would drop.
+var ErrUnsupportedFormat = errors.New("data file was written by a newer version of tasker")
+
+// columns maps each column name in the header of a data file to its index.
+type columns map[string]int
+
+// defaultColumns is the layout assumed when a header is unusable.
+var defaultColumns = func() columns {
+	c := make(columns, len(csvColumns))
+	for i, name := range csvColumns {
+		c[name] = i
+	}
+	return c
+}()
+
+// parseHeader maps the column names of a header. Unknown columns are read into the
+// Extra of each task, so rewriting the file keeps them.
+func parseHeader(header []string) (columns, error) {
+	c := make(columns, len(header))
+	for i, name := range header {
+		if _, ok := c[name]; ok {
+			return nil, fmt.Errorf("invalid header: duplicate column %s", name)
+		}
+		c[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := c[name]; !ok {
+			return nil, fmt.Errorf("invalid header: missing column %s", name)
+		}
+	}
+	return c, nil
+}
+
+// width is the number of fields a record needs to hold every required column.
+func (c columns) width() int {
+	width := 0
+	for _, name := range requiredColumns {
+		width = max(width, c[name]+1)
+	}
+	return width
+}
+
+// field returns the value of the named column in record, or "" when the file has no such column.
+func (c columns) field(record []string, name string) string {
+	i, ok := c[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// newCSVReader returns a reader for a data file, which skips the format marker.
+func newCSVReader(r io.Reader) *csv.Reader {
+	csvReader := csv.NewReader(r)
+	csvReader.Comment = '#'
+	// Trailing columns are optional so files from older versions still parse
+	csvReader.FieldsPerRecord = -1
+	return csvReader
+}
+
+// fileFormatVersion returns the format version recorded in the data file at path.
+func fileFormatVersion(path string) (int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return formatVersion, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	rest, ok := strings.CutPrefix(line, formatMarker)
+	if !ok {
+		return 1, nil
+	}
+	var version int
+	if _, err := fmt.Sscanf(rest, "%d", &version); err != nil {
+		return 0, fmt.Errorf("invalid format marker %q", strings.TrimSpace(line))
+	}
+	return version, nil
+}
+
+// checkFormat refuses to rewrite the file at path if it was written by a newer version.
+func checkFormat(path string) error {
+	version, err := fileFormatVersion(path)
+	if err != nil {
+		return err
+	}
+	if version > formatVersion {
+		return fmt.Errorf("%s: %w (format %d, supported up to %d)", path, ErrUnsupportedFormat, version, formatVersion)
+	}
+	return nil
+}
+
+// isCurrentFormat reports whether data starts with the header this version writes,
+// so records can be appended without rewriting the file.
+func isCurrentFormat(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(csvHeader))
+}
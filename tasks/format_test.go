@@ -0,0 +1,150 @@
+package tasks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTasksByColumnName(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		extra   map[string]string
+		wantErr string
+	}{
+		{
+			name: "Swapped columns",
+			data: "Description,IsComplete,ID,CreatedAt\nTest,true,1,2025-05-12T10:00:00Z\n",
+		},
+		{
+			name:  "Unknown columns",
+			data:  "# tasker format 9\nID,Priority,Description,CreatedAt,IsComplete,Due\n1,high,Test,2025-05-12T10:00:00Z,true,tomorrow\n",
+			extra: map[string]string{"Priority": "high", "Due": "tomorrow"},
+		},
+		{
+			name:    "Missing column",
+			data:    "ID,Description,IsComplete\n1,Test,true\n",
+			wantErr: "missing column CreatedAt",
+		},
+		{
+			name:    "Duplicate column",
+			data:    "ID,Description,CreatedAt,IsComplete,ID\n1,Test,2025-05-12T10:00:00Z,true,2\n",
+			wantErr: "duplicate column ID",
+		},
+	}
+	want := Task{ID: 1, Description: "Test", CreatedAt: time.Date(2025, 5, 12, 10, 0, 0, 0, time.UTC), IsCompleted: true}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tasks, err := readTasksFromCSVData([]byte(tc.data))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want.Extra = tc.extra
+			if len(tasks) != 1 || !sameTask(tasks[0], want) {
+				t.Errorf("got %+v, want %+v", tasks, want)
+			}
+		})
+	}
+}
+
+func TestFormatUpgrade(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	content := "ID,Description,CreatedAt,IsComplete\n1,Task1,2025-05-12T10:00:00Z,false\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if version, err := fileFormatVersion(tmpFile); err != nil || version != 1 {
+		t.Fatalf("fileFormatVersion() = %d, %v, want 1", version, err)
+	}
+
+	if err := AddTask(tmpFile, "Task2"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), csvHeader) {
+		t.Errorf("expected the file to be upgraded, got %q", data)
+	}
+	if version, err := fileFormatVersion(tmpFile); err != nil || version != formatVersion {
+		t.Errorf("fileFormatVersion() = %d, %v, want %d", version, err, formatVersion)
+	}
+	if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 2 {
+		t.Errorf("expected both tasks after the upgrade, got %v", got)
+	}
+}
+
+func TestNewerFormat(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	content := "# tasker format 99\nID,Description,CreatedAt,IsComplete,Priority\n1,Task1,2025-05-12T10:00:00Z,false,high\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 1 {
+		t.Errorf("expected the task to be readable, got %v", got)
+	}
+	if err := CompleteTask(tmpFile, "1"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("CompleteTask() error = %v, want ErrUnsupportedFormat", err)
+	}
+	if err := AddTask(tmpFile, "Task2"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("AddTask() error = %v, want ErrUnsupportedFormat", err)
+	}
+	data, err := os.ReadFile(tmpFile)
+	if err != nil || string(data) != content {
+		t.Errorf("expected the file to be left alone, got %q, %v", data, err)
+	}
+}
+
+func TestUnknownColumn(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	content := "# tasker format 2\nID,Description,CreatedAt,IsComplete,Notes\n1,Task1,2025-05-12T10:00:00Z,false,call back\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	if got := readAllTasks(t, tmpFile); len(got) != 1 || got[0].Extra["Notes"] != "call back" {
+		t.Errorf("expected the task to be readable with its note, got %+v", got)
+	}
+	if err := CompleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	if err := AddTask(tmpFile, "Task2"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	if want := "ID,Description,CreatedAt,IsComplete,DeletedAt,CompletedAt,UUID,Notes"; lines[1] != want {
+		t.Errorf("header = %q, want %q", lines[1], want)
+	}
+	tasks := readAllTasks(t, tmpFile)
+	if len(tasks) != 2 || !tasks[0].IsCompleted || tasks[0].Extra["Notes"] != "call back" || tasks[1].Extra["Notes"] != "" {
+		t.Errorf("expected the note to be kept, got %+v", tasks)
+	}
+
+	problems, err := Diagnose(tmpFile)
+	if err != nil {
+		t.Fatalf("Diagnose error: %v", err)
+	}
+	if len(problems) != 1 || !problems[0].Info || !strings.Contains(problems[0].Reason, "unknown column Notes") {
+		t.Errorf("expected the unknown column to be reported, got %+v", problems)
+	}
+	if fixed, err := Repair(tmpFile); err != nil || len(fixed) != 0 {
+		t.Errorf("Repair() = %+v, %v; want nothing to fix", fixed, err)
+	}
+}
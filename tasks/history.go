@@ -0,0 +1,152 @@
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Event is one entry in the append-only audit history of a task.
+type Event struct {
+	Time   time.Time     `json:"time"`
+	Op     string        `json:"op"`
+	TaskID int           `json:"task_id"`
+	User   string        `json:"user,omitempty"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is the old and new value of a single task field, formatted as in the data file.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+func historyPath(filename string) string {
+	return filename + ".history"
+}
+
+// fieldChanges lists the fields that differ between two states of a task, either of which may be nil.
+func fieldChanges(before, after *Task) []FieldChange {
+	names := csvColumns
+	old := make([]string, len(names))
+	if before != nil {
+		old = taskToRecord(*before)
+	}
+	cur := make([]string, len(names))
+	if after != nil {
+		cur = taskToRecord(*after)
+	}
+
+	var fields []FieldChange
+	for i, name := range names {
+		if old[i] != cur[i] {
+			fields = append(fields, FieldChange{Field: name, Old: old[i], New: cur[i]})
+		}
+	}
+	return fields
+}
+
+// Fields lists the task fields that differ between the Before and After state of the change.
+func (c Change) Fields() []FieldChange {
+	return fieldChanges(c.Before, c.After)
+}
+
+// recordHistory appends one event per change to the history of filename. The
+// history is never rewritten. It must be called while the datasource lock is held.
+func recordHistory(filename, op string, changes []Change) error {
+	f, err := os.OpenFile(historyPath(filename), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	now := time.Now()
+	user := os.Getenv("USER")
+	enc := json.NewEncoder(f)
+	for _, change := range changes {
+		event := Event{
+			Time:   now,
+			Op:     op,
+			TaskID: change.ID,
+			User:   user,
+			Fields: fieldChanges(change.Before, change.After),
+		}
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	return nil
+}
+
+// readHistory returns the events in the history of the data file that match keep, oldest first.
+func (c *Client) readHistory(ctx context.Context, keep func(Event) bool) (_ []Event, err error) {
+	file, err := c.open(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer closeLocked(file, &err)
+
+	f, err := os.Open(historyPath(c.filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("malformed history entry on line %d: %w", line, err)
+		}
+		if keep(event) {
+			events = append(events, event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return events, nil
+}
+
+// TaskHistory returns every recorded event for the given task, oldest first.
+// The task may be given by UUID as long as it has not been purged.
+func TaskHistory(filename string, taskID string) ([]Event, error) {
+	ref, err := parseTaskRef(taskID)
+	if err != nil {
+		return nil, err
+	}
+	c := New(filename)
+	id := ref.id
+	if ref.uuid != "" {
+		task, err := c.get(context.Background(), ref)
+		if err != nil {
+			return nil, err
+		}
+		id = task.ID
+	}
+	return c.TaskHistory(context.Background(), id)
+}
+
+// TaskHistory returns every recorded event for the task with the given ID, oldest first.
+func (c *Client) TaskHistory(ctx context.Context, id int) ([]Event, error) {
+	return c.readHistory(ctx, func(e Event) bool { return e.TaskID == id })
+}
+
+// History returns every recorded event since the given time, oldest first.
+func History(filename string, since time.Time) ([]Event, error) {
+	return New(filename).History(context.Background(), since)
+}
+
+// History returns every recorded event since the given time, oldest first.
+func (c *Client) History(ctx context.Context, since time.Time) ([]Event, error) {
+	return c.readHistory(ctx, func(e Event) bool { return !e.Time.Before(since) })
+}
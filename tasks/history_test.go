@@ -0,0 +1,68 @@
+package tasks
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTaskHistory(t *testing.T) {
+	t.Setenv("USER", "alice")
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	if err := AddTask(tmpFile, "Task2"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	if err := CompleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	if _, err := Undo(tmpFile); err != nil {
+		t.Fatalf("Undo error: %v", err)
+	}
+
+	t.Run("records every mutation of the task", func(t *testing.T) {
+		events, err := TaskHistory(tmpFile, "1")
+		if err != nil {
+			t.Fatalf("TaskHistory error: %v", err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("expected 3 events, got %+v", events)
+		}
+		for i, op := range []string{"add", "complete", "undo"} {
+			if events[i].Op != op || events[i].User != "alice" || events[i].TaskID != 1 {
+				t.Errorf("event %d = %+v, want op %q by alice", i, events[i], op)
+			}
+		}
+	})
+
+	t.Run("records old and new values", func(t *testing.T) {
+		events, err := TaskHistory(tmpFile, "1")
+		if err != nil {
+			t.Fatalf("TaskHistory error: %v", err)
+		}
+		want := FieldChange{Field: "IsComplete", Old: "true", New: "false"}
+		if len(events[2].Fields) == 0 || events[2].Fields[0] != want {
+			t.Errorf("undo fields = %+v, want first %+v", events[2].Fields, want)
+		}
+	})
+
+	t.Run("history since filters by time", func(t *testing.T) {
+		events, err := History(tmpFile, time.Time{})
+		if err != nil {
+			t.Fatalf("History error: %v", err)
+		}
+		if len(events) != 4 {
+			t.Errorf("expected 4 events in total, got %d", len(events))
+		}
+		events, err = History(tmpFile, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("History error: %v", err)
+		}
+		if len(events) != 0 {
+			t.Errorf("expected no future events, got %+v", events)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package tasks
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// taskRef identifies a task either by its short numeric ID or by its UUID.
+type taskRef struct {
+	id   int
+	uuid string
+}
+
+// parseTaskRef accepts a numeric task ID such as "12" or a UUID.
+func parseTaskRef(taskID string) (taskRef, error) {
+	if id, err := strconv.Atoi(taskID); err == nil {
+		return taskRef{id: id}, nil
+	}
+	if uuid := strings.ToLower(taskID); uuidPattern.MatchString(uuid) {
+		return taskRef{uuid: uuid}, nil
+	}
+	return taskRef{}, fmt.Errorf("failed to parse task ID: %q is neither a number nor a UUID", taskID)
+}
+
+// parseTaskRefs parses every task ID, failing on the first that is invalid.
+func parseTaskRefs(taskIDs []string) ([]taskRef, error) {
+	refs := make([]taskRef, len(taskIDs))
+	for i, taskID := range taskIDs {
+		ref, err := parseTaskRef(taskID)
+		if err != nil {
+			return nil, err
+		}
+		refs[i] = ref
+	}
+	return refs, nil
+}
+
+// idRefs refers to tasks by their short IDs.
+func idRefs(ids []int) []taskRef {
+	refs := make([]taskRef, len(ids))
+	for i, id := range ids {
+		refs[i] = taskRef{id: id}
+	}
+	return refs
+}
+
+func (r taskRef) matches(task Task) bool {
+	if r.uuid != "" {
+		return task.UUID == r.uuid
+	}
+	return task.ID == r.id
+}
+
+func (r taskRef) String() string {
+	if r.uuid != "" {
+		return r.uuid
+	}
+	return strconv.Itoa(r.id)
+}
+
+// seqPath returns the file holding the highest short ID ever issued for filename.
+func seqPath(filename string) string {
+	return filename + ".seq"
+}
+
+// fileStamp identifies the contents of a data file without reading it. Every
+// change tasker makes either replaces the file, giving it a new inode, or appends
+// to it, changing its size.
+type fileStamp struct {
+	size  int64
+	inode uint64
+}
+
+func (s fileStamp) String() string {
+	return fmt.Sprintf("%d %d", s.size, s.inode)
+}
+
+// stampOf returns the stamp of the file described by info.
+func stampOf(info os.FileInfo) fileStamp {
+	stamp := fileStamp{size: info.Size()}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		stamp.inode = uint64(st.Ino)
+	}
+	return stamp
+}
+
+// readTaskCounter returns the highest short ID recorded in the ID counter of
+// filename and the stamp of the data file it was written for, and false when there
+// is no counter yet. Counters written before stamps were recorded have a zero stamp.
+func readTaskCounter(filename string) (int, fileStamp, bool, error) {
+	data, err := os.ReadFile(seqPath(filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, fileStamp{}, false, nil
+	}
+	if err != nil {
+		return 0, fileStamp{}, false, fmt.Errorf("failed to read ID counter: %w", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fileStamp{}, false, errors.New("failed to parse ID counter: empty file")
+	}
+	last, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, fileStamp{}, false, fmt.Errorf("failed to parse ID counter: %w", err)
+	}
+	var stamp fileStamp
+	if len(fields) == 3 {
+		// A stamp that does not parse stays zero, which never matches a data file
+		stamp.size, _ = strconv.ParseInt(fields[1], 10, 64)
+		stamp.inode, _ = strconv.ParseUint(fields[2], 10, 64)
+	}
+	return last, stamp, true, nil
+}
+
+// nextTaskID returns the next short ID for filename given every task in it. IDs are
+// never reused, even after the task holding the highest ID is deleted, undone or
+// archived. It must be called while the datasource lock is held.
+func nextTaskID(filename string, tasks []Task) (int, error) {
+	last, _, ok, err := readTaskCounter(filename)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		// Files from before the counter existed: continue after the archive too
+		archived, err := readArchive(filename)
+		if err != nil {
+			return 0, err
+		}
+		for _, task := range archived {
+			last = max(last, task.ID)
+		}
+	}
+	for _, task := range tasks {
+		last = max(last, task.ID)
+	}
+	return last + 1, nil
+}
+
+// quickNextTaskID returns the next short ID for filename from the ID counter alone,
+// without reading the data file. It returns 0 when the data file is no longer the
+// one the counter was written for, since the file may then hold higher IDs (a hand
+// edit, or an add interrupted before updating the counter). It must be called while
+// the datasource lock is held.
+func quickNextTaskID(filename string) (int, error) {
+	last, stamp, ok, err := readTaskCounter(filename)
+	if err != nil || !ok {
+		return 0, err
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if stampOf(info) != stamp {
+		return 0, nil
+	}
+	return last + 1, nil
+}
+
+// saveTaskID records id as the highest short ID issued for filename, together with
+// the stamp of the data file. It is called after the task has been written to the
+// data file, so that quickNextTaskID trusts the counter until the file changes.
+func saveTaskID(filename string, id int) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	err = replaceFile(seqPath(filename), func(f *os.File) error {
+		_, err := fmt.Fprintln(f, id, stampOf(info))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write ID counter: %w", err)
+	}
+	return nil
+}
+
+// assignMissingUUIDs gives tasks written before UUIDs existed a UUID of their own.
+func assignMissingUUIDs(tasks []Task) {
+	for i := range tasks {
+		if tasks[i].UUID == "" {
+			tasks[i].UUID = newUUID()
+		}
+	}
+}
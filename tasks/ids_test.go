@@ -0,0 +1,211 @@
+package tasks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTaskRef(t *testing.T) {
+	cases := []struct {
+		input   string
+		want    taskRef
+		wantErr bool
+	}{
+		{input: "12", want: taskRef{id: 12}},
+		{input: "0B0E7A4E-5C1F-4F0E-9A57-3F4BE3A1C2D9", want: taskRef{uuid: "0b0e7a4e-5c1f-4f0e-9a57-3f4be3a1c2d9"}},
+		{input: "notanumber", wantErr: true},
+		{input: "0b0e7a4e", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := parseTaskRef(tc.input)
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "failed to parse task ID") {
+					t.Errorf("expected parse error, got %v", err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("parseTaskRef(%q) = %+v, %v; want %+v", tc.input, got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewUUID(t *testing.T) {
+	a, b := newUUID(), newUUID()
+	if !uuidPattern.MatchString(a) || a == b {
+		t.Errorf("expected two distinct UUIDs, got %q and %q", a, b)
+	}
+}
+
+func TestIDsAreNeverReused(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	for _, description := range []string{"Task1", "Task2"} {
+		if err := AddTask(tmpFile, description); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	if err := DeleteTask(tmpFile, "2"); err != nil {
+		t.Fatalf("DeleteTask error: %v", err)
+	}
+	if _, err := PurgeTasks(tmpFile, 0); err != nil {
+		t.Fatalf("PurgeTasks error: %v", err)
+	}
+	if err := AddTask(tmpFile, "Task3"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+
+	tasks := readAllTasks(t, tmpFile)
+	if got := taskIDs(tasks); len(got) != 2 || got[1] != 3 {
+		t.Errorf("expected the new task to get ID 3, got %v", got)
+	}
+	if tasks[0].UUID == "" || tasks[0].UUID == tasks[1].UUID {
+		t.Errorf("expected distinct UUIDs, got %+v", tasks)
+	}
+}
+
+func TestCommandsAcceptUUIDs(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	uuid := readAllTasks(t, tmpFile)[0].UUID
+
+	if err := CompleteTask(tmpFile, uuid); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	task, err := GetTask(tmpFile, strings.ToUpper(uuid))
+	if err != nil {
+		t.Fatalf("GetTask error: %v", err)
+	}
+	if task.ID != 1 || !task.IsCompleted {
+		t.Errorf("expected task 1 to be completed by UUID, got %+v", task)
+	}
+}
+
+func TestLegacyTasksGetUUIDOnWrite(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	content := "ID,Description,CreatedAt,IsComplete\n1,Task1,2025-05-12T10:00:00Z,false\n2,Task2,2025-05-12T11:00:00Z,false\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if err := CompleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	for _, task := range readAllTasks(t, tmpFile) {
+		if task.UUID == "" {
+			t.Errorf("expected task %d to get a UUID", task.ID)
+		}
+	}
+	if err := AddTask(tmpFile, "Task3"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	if got := taskIDs(readAllTasks(t, tmpFile)); got[len(got)-1] != 3 {
+		t.Errorf("expected new task to continue after existing IDs, got %v", got)
+	}
+}
+
+func TestAddTaskFastPath(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+
+	t.Run("appends without reading the tasks", func(t *testing.T) {
+		// A row that fails to parse shows whether the records were read
+		f, err := os.OpenFile(tmpFile, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = f.WriteString("x,Unparsable,never,maybe")
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The counter is taken to be written for the file as it is now
+		if err := saveTaskID(tmpFile, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := AddTask(tmpFile, "Task2"); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+		data, err := os.ReadFile(tmpFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "x,Unparsable,never,maybe\n2,Task2,") {
+			t.Errorf("expected task 2 appended on its own line, got %q", data)
+		}
+	})
+
+	t.Run("reads the tasks after the file was edited", func(t *testing.T) {
+		if err := os.WriteFile(tmpFile, []byte(csvHeader+"1,Task1,2025-05-12T10:00:00Z,false\n7,Edited,2025-05-12T10:00:00Z,false\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := AddTask(tmpFile, "Task8"); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 3 || got[2] != 8 {
+			t.Errorf("expected the new task to get ID 8, got %v", got)
+		}
+	})
+
+	t.Run("reads the tasks after the file was replaced with one of the same size", func(t *testing.T) {
+		data, err := os.ReadFile(tmpFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Saved the way editors do, by writing a new file and renaming it over the old one
+		edited := filepath.Join(filepath.Dir(tmpFile), "edited.csv")
+		if err := os.WriteFile(edited, []byte(strings.Replace(string(data), "\n7,Edited,", "\n9,Edited,", 1)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(edited, tmpFile); err != nil {
+			t.Fatal(err)
+		}
+		if err := AddTask(tmpFile, "Task10"); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 4 || got[3] != 10 {
+			t.Errorf("expected the new task to get ID 10, got %v", got)
+		}
+	})
+}
+
+// BenchmarkAddTask compares appending with an up-to-date ID counter against reading
+// every task first, as happens when the data file changed since the last add.
+func BenchmarkAddTask(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		b.Run(fmt.Sprintf("append/%d", n), func(b *testing.B) {
+			tmpFile := writeBenchmarkFile(b, n)
+			// The first add has no counter yet and reads every task
+			if err := AddTask(tmpFile, "Benchmark"); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := AddTask(tmpFile, "Benchmark"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("read-all/%d", n), func(b *testing.B) {
+			tmpFile := writeBenchmarkFile(b, n)
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := os.Remove(seqPath(tmpFile)); err != nil && !os.IsNotExist(err) {
+					b.Fatal(err)
+				}
+				if err := AddTask(tmpFile, "Benchmark"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// maxJournalEntries bounds how many operations can be undone.
+const maxJournalEntries = 50
+
+// maxJournalSize is the size past which the journal is compacted. Until then it is
+// only appended to, so recording a change does not depend on how large it is.
+const maxJournalSize = 1 << 20
+
+var (
+	// ErrNothingToUndo is returned by Undo when the journal has no entries left to revert.
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo is returned by Redo when no undone entry can be reapplied.
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// Change records the state of a single task before and after a mutation.
+// A nil Before means the task was created, a nil After means it was removed.
+type Change struct {
+	ID     int   `json:"id"`
+	Before *Task `json:"before,omitempty"`
+	After  *Task `json:"after,omitempty"`
+}
+
+// JournalEntry is one mutating operation that can be undone and redone.
+type JournalEntry struct {
+	Op      string    `json:"op"`
+	Time    time.Time `json:"time"`
+	Changes []Change  `json:"changes"`
+}
+
+// journal is the undo history. Entries before Position can be undone, entries
+// from Position onwards have been undone and can be redone.
+type journal struct {
+	Entries  []JournalEntry `json:"entries"`
+	Position int            `json:"position"`
+}
+
+// journalRecord is one line of the journal file. A line holding Entries replaces
+// the whole journal, as written when it is compacted; an Entry is added at the
+// current position, discarding anything that was undone; a Position moves it, as
+// undo and redo do.
+type journalRecord struct {
+	Entries  []JournalEntry `json:"entries,omitempty"`
+	Entry    *JournalEntry  `json:"entry,omitempty"`
+	Position *int           `json:"position,omitempty"`
+}
+
+func journalPath(filename string) string {
+	return filename + ".journal"
+}
+
+func loadJournal(filename string) (journal, error) {
+	var j journal
+	data, err := os.ReadFile(journalPath(filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return j, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	lineNo := 0
+	for line := range bytes.Lines(data) {
+		lineNo++
+		var record journalRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			// A final line left incomplete by a crash is ignored
+			if !bytes.HasSuffix(line, []byte("\n")) {
+				break
+			}
+			return j, fmt.Errorf("failed to parse journal: line %d: %w", lineNo, err)
+		}
+		if record.Entries != nil {
+			j.Entries = record.Entries
+			j.Position = len(j.Entries)
+		}
+		if record.Entry != nil {
+			j.Entries = append(j.Entries[:j.Position], *record.Entry)
+			j.Position = len(j.Entries)
+		}
+		if record.Position != nil {
+			j.Position = *record.Position
+		}
+		if j.Position < 0 || j.Position > len(j.Entries) {
+			j.Position = len(j.Entries)
+		}
+	}
+
+	if drop := len(j.Entries) - maxJournalEntries; drop > 0 {
+		j.Entries = j.Entries[drop:]
+		j.Position = max(0, j.Position-drop)
+	}
+	return j, nil
+}
+
+// appendJournal adds record to the end of the journal of filename, first cutting
+// off a final line left incomplete by a crash. The journal is compacted once it
+// grows past maxJournalSize.
+func appendJournal(filename string, record journalRecord) (err error) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+	f, err := os.OpenFile(journalPath(filename), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write journal: %w", closeErr)
+		}
+	}()
+
+	end, err := completeLength(f)
+	if err != nil {
+		return err
+	}
+	// Journals written before it was appended to hold a single line without a newline
+	tail, err := readTail(f, end)
+	if err != nil {
+		return err
+	}
+	if len(tail) > 0 && json.Valid(tail) {
+		line = append([]byte("\n"), line...)
+		end += int64(len(tail))
+	}
+	if err := f.Truncate(end); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+	if _, err := f.WriteAt(append(line, '\n'), end); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if end+int64(len(line))+1 <= maxJournalSize {
+		return nil
+	}
+	return compactJournal(filename)
+}
+
+// readTail returns the contents of f after offset.
+func readTail(f *os.File, offset int64) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat journal: %w", err)
+	}
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return tail, nil
+}
+
+// compactJournal rewrites the journal of filename as a single line, dropping the
+// oldest entries until it takes at most half of maxJournalSize, so that it is not
+// compacted again on the next change. The latest entry is always kept.
+func compactJournal(filename string) error {
+	j, err := loadJournal(filename)
+	if err != nil {
+		return err
+	}
+	size := 0
+	sizes := make([]int, len(j.Entries))
+	for i, entry := range j.Entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode journal: %w", err)
+		}
+		sizes[i] = len(data) + 1
+		size += sizes[i]
+	}
+	drop := 0
+	for size > maxJournalSize/2 && drop < len(j.Entries)-1 {
+		size -= sizes[drop]
+		drop++
+	}
+	j.Entries = j.Entries[drop:]
+	j.Position = max(0, j.Position-drop)
+
+	data, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+	err = replaceFile(journalPath(filename), func(f *os.File) error {
+		_, err := f.Write(append(data, '\n'))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// diffTasks returns the tasks that differ between two snapshots of the datasource.
+func diffTasks(before, after []Task) []Change {
+	old := make(map[int]Task, len(before))
+	for _, task := range before {
+		old[task.ID] = task
+	}
+
+	var changes []Change
+	for _, task := range after {
+		prev, ok := old[task.ID]
+		delete(old, task.ID)
+		if ok && sameTask(prev, task) {
+			continue
+		}
+		change := Change{ID: task.ID, After: &task}
+		if ok {
+			change.Before = &prev
+		}
+		changes = append(changes, change)
+	}
+	for _, task := range before {
+		if prev, ok := old[task.ID]; ok {
+			changes = append(changes, Change{ID: task.ID, Before: &prev})
+		}
+	}
+	return changes
+}
+
+func sameTask(a, b Task) bool {
+	return a.ID == b.ID &&
+		a.Description == b.Description &&
+		a.CreatedAt.Equal(b.CreatedAt) &&
+		a.IsCompleted == b.IsCompleted &&
+		a.DeletedAt.Equal(b.DeletedAt) &&
+		a.CompletedAt.Equal(b.CompletedAt) &&
+		a.UUID == b.UUID &&
+		maps.Equal(a.Extra, b.Extra)
+}
+
+// recordChange appends changes to the journal of filename as op, discarding anything
+// that was previously undone, and to the audit history. Automatic archiving only goes
+// to the history. It must be called while the datasource lock is held.
+func recordChange(filename, op string, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := recordHistory(filename, op, changes); err != nil {
+		return err
+	}
+	if op == opAutoArchive {
+		return nil
+	}
+
+	return appendJournal(filename, journalRecord{Entry: &JournalEntry{Op: op, Time: time.Now(), Changes: changes}})
+}
+
+// applyChanges sets every changed task to its Before (undo) or After (redo) state.
+func applyChanges(tasks []Task, changes []Change, undo bool) []Task {
+	for _, change := range changes {
+		state := change.After
+		if undo {
+			state = change.Before
+		}
+		tasks = slices.DeleteFunc(tasks, func(t Task) bool { return t.ID == change.ID })
+		if state != nil {
+			tasks = append(tasks, *state)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b Task) int { return a.ID - b.ID })
+	return tasks
+}
+
+// invertChanges swaps the before and after state of every change.
+func invertChanges(changes []Change) []Change {
+	inverted := make([]Change, len(changes))
+	for i, change := range changes {
+		inverted[i] = Change{ID: change.ID, Before: change.After, After: change.Before}
+	}
+	return inverted
+}
+
+// Undo reverts the most recent operation recorded in the journal and returns it.
+func Undo(filename string) (JournalEntry, error) {
+	return New(filename).Undo(context.Background())
+}
+
+// Redo reapplies the most recently undone operation and returns it.
+func Redo(filename string) (JournalEntry, error) {
+	return New(filename).Redo(context.Background())
+}
+
+// Undo reverts the most recent operation recorded in the journal and returns it.
+func (c *Client) Undo(ctx context.Context) (JournalEntry, error) {
+	return c.replayJournal(ctx, true)
+}
+
+// Redo reapplies the most recently undone operation and returns it.
+func (c *Client) Redo(ctx context.Context) (JournalEntry, error) {
+	return c.replayJournal(ctx, false)
+}
+
+func (c *Client) replayJournal(ctx context.Context, undo bool) (_ JournalEntry, err error) {
+	file, err := c.open(ctx, true)
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to open datasource for updating: %w", err)
+	}
+	defer closeLocked(file, &err)
+
+	j, err := loadJournal(c.filename)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	var entry JournalEntry
+	if undo {
+		if j.Position == 0 {
+			return JournalEntry{}, ErrNothingToUndo
+		}
+		j.Position--
+		entry = j.Entries[j.Position]
+	} else {
+		if j.Position == len(j.Entries) {
+			return JournalEntry{}, ErrNothingToRedo
+		}
+		entry = j.Entries[j.Position]
+		j.Position++
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	tasks, err := decodeTasks(c.filename, data)
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to parse tasks: %w", err)
+	}
+	before := slices.Clone(tasks)
+	if entry.Op == "archive" {
+		if err := replayArchive(c.filename, entry.Changes, undo); err != nil {
+			return JournalEntry{}, err
+		}
+	} else if tasks, err = unarchive(c.filename, tasks, entry.Changes); err != nil {
+		return JournalEntry{}, err
+	}
+
+	op, changes := "redo", entry.Changes
+	if undo {
+		op, changes = "undo", invertChanges(entry.Changes)
+	}
+	after := applyChanges(tasks, entry.Changes, undo)
+	if err := saveChanges(file, data, after, diffTasks(before, after)); err != nil {
+		return JournalEntry{}, err
+	}
+	if err := recordHistory(c.filename, op, changes); err != nil {
+		return JournalEntry{}, err
+	}
+	return entry, appendJournal(c.filename, journalRecord{Position: &j.Position})
+}
+
+// UndoList returns the operations that can be undone, most recent first.
+func UndoList(filename string) ([]JournalEntry, error) {
+	return New(filename).UndoList(context.Background())
+}
+
+// UndoList returns the operations that can be undone, most recent first.
+func (c *Client) UndoList(ctx context.Context) (_ []JournalEntry, err error) {
+	file, err := c.open(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer closeLocked(file, &err)
+
+	j, err := loadJournal(c.filename)
+	if err != nil {
+		return nil, err
+	}
+	entries := slices.Clone(j.Entries[:j.Position])
+	slices.Reverse(entries)
+	return entries, nil
+}
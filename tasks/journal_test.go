@@ -0,0 +1,218 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func taskIDs(tasks []Task) []int {
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestUndoRedo(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	for _, description := range []string{"Task1", "Task2", "Task3"} {
+		if err := AddTask(tmpFile, description); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	if err := DeleteTask(tmpFile, "2"); err != nil {
+		t.Fatalf("DeleteTask error: %v", err)
+	}
+	if err := CompleteTask(tmpFile, "3"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+
+	t.Run("undo list shows most recent first", func(t *testing.T) {
+		entries, err := UndoList(tmpFile)
+		if err != nil {
+			t.Fatalf("UndoList error: %v", err)
+		}
+		if len(entries) != 5 || entries[0].Op != "complete" || entries[1].Op != "delete" {
+			t.Fatalf("unexpected undo list: %+v", entries)
+		}
+	})
+
+	t.Run("undo reverts complete then delete", func(t *testing.T) {
+		entry, err := Undo(tmpFile)
+		if err != nil || entry.Op != "complete" {
+			t.Fatalf("Undo() = %+v, %v", entry, err)
+		}
+		tasks := readAllTasks(t, tmpFile)
+		if tasks[len(tasks)-1].IsCompleted {
+			t.Errorf("expected task 3 to be uncompleted after undo")
+		}
+
+		entry, err = Undo(tmpFile)
+		if err != nil || entry.Op != "delete" {
+			t.Fatalf("Undo() = %+v, %v", entry, err)
+		}
+		tasks = readAllTasks(t, tmpFile)
+		if got := taskIDs(tasks); len(got) != 3 || got[1] != 2 || tasks[1].IsDeleted() {
+			t.Errorf("expected task 2 restored in place, got %+v", tasks)
+		}
+	})
+
+	t.Run("redo reapplies the delete", func(t *testing.T) {
+		entry, err := Redo(tmpFile)
+		if err != nil || entry.Op != "delete" {
+			t.Fatalf("Redo() = %+v, %v", entry, err)
+		}
+		if tasks := readAllTasks(t, tmpFile); !tasks[1].IsDeleted() {
+			t.Errorf("expected task 2 deleted again, got %+v", tasks)
+		}
+	})
+
+	t.Run("new change discards redo history", func(t *testing.T) {
+		if err := AddTask(tmpFile, "Task4"); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+		if _, err := Redo(tmpFile); !errors.Is(err, ErrNothingToRedo) {
+			t.Errorf("expected ErrNothingToRedo, got %v", err)
+		}
+	})
+
+	t.Run("undo stops at the start of the journal", func(t *testing.T) {
+		for {
+			if _, err := Undo(tmpFile); err != nil {
+				if !errors.Is(err, ErrNothingToUndo) {
+					t.Fatalf("expected ErrNothingToUndo, got %v", err)
+				}
+				break
+			}
+		}
+		if tasks := readAllTasks(t, tmpFile); len(tasks) != 0 {
+			t.Errorf("expected no tasks after undoing everything, got %+v", tasks)
+		}
+	})
+}
+
+func TestJournalIsBounded(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	for i := 0; i < maxJournalEntries+5; i++ {
+		if err := AddTask(tmpFile, "Task"); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	entries, err := UndoList(tmpFile)
+	if err != nil {
+		t.Fatalf("UndoList error: %v", err)
+	}
+	if len(entries) != maxJournalEntries {
+		t.Errorf("expected %d journal entries, got %d", maxJournalEntries, len(entries))
+	}
+}
+
+func TestJournalIsAppendedTo(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	for _, description := range []string{"Task1", "Task2"} {
+		if err := AddTask(tmpFile, description); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	before, err := os.ReadFile(journalPath(tmpFile))
+	if err != nil {
+		t.Fatalf("failed to read journal: %v", err)
+	}
+
+	t.Run("changes and undo only add lines", func(t *testing.T) {
+		if err := AddTask(tmpFile, "Task3"); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+		if _, err := Undo(tmpFile); err != nil {
+			t.Fatalf("Undo error: %v", err)
+		}
+		after, err := os.ReadFile(journalPath(tmpFile))
+		if err != nil {
+			t.Fatalf("failed to read journal: %v", err)
+		}
+		if !bytes.HasPrefix(after, before) || bytes.Count(after, []byte("\n")) != 4 {
+			t.Errorf("expected two lines appended to %q, got %q", before, after)
+		}
+	})
+
+	t.Run("an incomplete last line is cut off", func(t *testing.T) {
+		f, err := os.OpenFile(journalPath(tmpFile), os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = f.WriteString(`{"entry":{"op":"ad`)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := CompleteTask(tmpFile, "1"); err != nil {
+			t.Fatalf("CompleteTask error: %v", err)
+		}
+		entries, err := UndoList(tmpFile)
+		if err != nil || len(entries) != 3 || entries[0].Op != "complete" {
+			t.Errorf("UndoList() = %+v, %v; want complete and both adds", entries, err)
+		}
+	})
+}
+
+func TestJournalFromBeforeAppending(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	// Older versions wrote the whole journal as one object without a newline
+	j, err := loadJournal(tmpFile)
+	if err != nil {
+		t.Fatalf("loadJournal error: %v", err)
+	}
+	data, err := json.Marshal(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(journalPath(tmpFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddTask(tmpFile, "Task2"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	entries, err := UndoList(tmpFile)
+	if err != nil || len(entries) != 2 {
+		t.Errorf("UndoList() = %+v, %v; want both adds", entries, err)
+	}
+}
+
+func TestJournalIsCompacted(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	description := strings.Repeat("x", maxJournalSize/8)
+	for range 12 {
+		if err := AddTask(tmpFile, description); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+
+	info, err := os.Stat(journalPath(tmpFile))
+	if err != nil {
+		t.Fatalf("failed to stat journal: %v", err)
+	}
+	if info.Size() > maxJournalSize {
+		t.Errorf("expected the journal to stay within %d bytes, got %d", maxJournalSize, info.Size())
+	}
+	entries, err := UndoList(tmpFile)
+	if err != nil || len(entries) == 0 || len(entries) >= 12 {
+		t.Fatalf("UndoList() = %d entries, %v; want the latest ones", len(entries), err)
+	}
+	if _, err := Undo(tmpFile); err != nil {
+		t.Fatalf("Undo error: %v", err)
+	}
+	if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 11 {
+		t.Errorf("expected the last add undone, got %v", got)
+	}
+}
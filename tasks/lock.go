@@ -0,0 +1,205 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// NoWait can be assigned to LockTimeout to fail immediately when the datasource is locked.
+const NoWait time.Duration = -1
+
+// LockTimeout bounds how long operations wait for another process to release the
+// datasource lock. Zero waits forever and NoWait does not wait at all.
+var LockTimeout time.Duration
+
+// lockPollInterval is how often a locked datasource is retried while waiting.
+const lockPollInterval = 50 * time.Millisecond
+
+// ErrLocked is returned when the datasource lock could not be acquired in time.
+type ErrLocked struct {
+	// Path is the lock file.
+	Path string
+	// PID is the process holding the lock, or 0 when it is not known.
+	PID int
+}
+
+func (e *ErrLocked) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("datasource is locked by another process (%s)", e.Path)
+	}
+	return fmt.Sprintf("datasource is locked by process %d (%s)", e.PID, e.Path)
+}
+
+// lockedFile is the data file opened while holding its coordination lock.
+// The lock lives in a separate file so the data file can be replaced by rename.
+type lockedFile struct {
+	*os.File
+	lock      *os.File
+	exclusive bool
+	// client opened the file; its settings apply to writes.
+	client *Client
+}
+
+func lockPath(filepath string) string {
+	return filepath + ".lock"
+}
+
+// open opens the data file under its lock: exclusive for updating, or shared for
+// reading so readers only wait for writers and never for each other. It waits at
+// most the lock timeout of the client, and never after ctx is done.
+func (c *Client) open(ctx context.Context, exclusive bool) (*lockedFile, error) {
+	lock, err := os.OpenFile(lockPath(c.filename), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if err := acquireLock(ctx, lock, how, c.lockTimeout); err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	if exclusive {
+		// Record the holder so waiting processes can tell who they are waiting for
+		_ = lock.Truncate(0)
+		_, _ = lock.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	if err := ensureDataSource(c.filename); err != nil {
+		_ = unlockFile(lock, exclusive)
+		return nil, err
+	}
+	flag := os.O_RDONLY
+	if exclusive {
+		flag = os.O_RDWR
+	}
+	f, err := os.OpenFile(c.filename, flag, 0)
+	if err != nil {
+		_ = unlockFile(lock, exclusive)
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return &lockedFile{File: f, lock: lock, exclusive: exclusive, client: c}, nil
+}
+
+// acquireLock takes a flock of the given kind on lock. While a conflicting lock is
+// held elsewhere it polls until timeout, or until ctx is done.
+func acquireLock(ctx context.Context, lock *os.File, how int, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if timeout == 0 && ctx.Done() == nil {
+		return syscall.Flock(int(lock.Fd()), how)
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		err := syscall.Flock(int(lock.Fd()), how|syscall.LOCK_NB)
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			return err
+		}
+		if timeout < 0 {
+			return &ErrLocked{Path: lock.Name(), PID: readLockPID(lock)}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s: %w", lock.Name(), ctx.Err())
+		case <-deadline:
+			return &ErrLocked{Path: lock.Name(), PID: readLockPID(lock)}
+		case <-ticker.C:
+		}
+	}
+}
+
+// readLockPID returns the PID recorded in the lock file, or 0 if there is none.
+func readLockPID(lock *os.File) int {
+	data, err := io.ReadAll(io.NewSectionReader(lock, 0, 32))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+func unlockFile(lock *os.File, exclusive bool) error {
+	if exclusive {
+		_ = lock.Truncate(0)
+	}
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_UN); err != nil {
+		_ = lock.Close()
+		return fmt.Errorf("failed to unlock file: %w", err)
+	}
+	return lock.Close()
+}
+
+func closeFile(f *lockedFile) error {
+	if err := f.File.Close(); err != nil {
+		_ = unlockFile(f.lock, f.exclusive)
+		return err
+	}
+	return unlockFile(f.lock, f.exclusive)
+}
+
+// LockInfo describes the current state of the datasource lock.
+type LockInfo struct {
+	// Path is the lock file.
+	Path string
+	// Locked reports whether another process holds the lock.
+	Locked bool
+	// Shared reports whether the lock is only held by readers.
+	Shared bool
+	// PID is the process holding the lock exclusively, or 0 when not known.
+	PID int
+}
+
+// LockStatus reports whether the datasource lock is currently held, without waiting for it.
+func LockStatus(filename string) (LockInfo, error) {
+	return New(filename).LockStatus()
+}
+
+// LockStatus reports whether the lock of the data file is currently held, without waiting for it.
+func (c *Client) LockStatus() (LockInfo, error) {
+	info := LockInfo{Path: lockPath(c.filename)}
+	lock, err := os.Open(info.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return info, nil
+	}
+	if err != nil {
+		return info, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer lock.Close()
+
+	for _, how := range []int{syscall.LOCK_EX, syscall.LOCK_SH} {
+		err := syscall.Flock(int(lock.Fd()), how|syscall.LOCK_NB)
+		switch {
+		case err == nil:
+			_ = syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+			// Only readers hold it when a shared lock can still be taken
+			info.Shared = info.Locked
+			return info, nil
+		case errors.Is(err, syscall.EWOULDBLOCK):
+			info.Locked = true
+		default:
+			return info, fmt.Errorf("failed to check lock: %w", err)
+		}
+	}
+	info.PID = readLockPID(lock)
+	return info, nil
+}
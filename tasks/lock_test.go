@@ -0,0 +1,89 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// holdLock takes the datasource lock of filename like another tasker process would.
+func holdLock(t *testing.T, filename string) {
+	t.Helper()
+	f, err := New(filename).open(context.Background(), true)
+	if err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+	t.Cleanup(func() { closeFile(f) })
+}
+
+func withLockTimeout(t *testing.T, timeout time.Duration) {
+	t.Helper()
+	orig := LockTimeout
+	LockTimeout = timeout
+	t.Cleanup(func() { LockTimeout = orig })
+}
+
+func TestLockTimeout(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	holdLock(t, tmpFile)
+
+	t.Run("no wait fails immediately with holder PID", func(t *testing.T) {
+		withLockTimeout(t, NoWait)
+		_, err := ListTasks(tmpFile, true)
+		var locked *ErrLocked
+		if !errors.As(err, &locked) {
+			t.Fatalf("expected ErrLocked, got %v", err)
+		}
+		if locked.PID != os.Getpid() {
+			t.Errorf("expected holder PID %d, got %d", os.Getpid(), locked.PID)
+		}
+	})
+
+	t.Run("timeout waits before failing", func(t *testing.T) {
+		withLockTimeout(t, 150*time.Millisecond)
+		start := time.Now()
+		err := AddTask(tmpFile, "Task")
+		var locked *ErrLocked
+		if !errors.As(err, &locked) {
+			t.Fatalf("expected ErrLocked, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("expected to wait for the timeout, returned after %v", elapsed)
+		}
+	})
+}
+
+func TestLockAcquiredWhenReleased(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	f, err := New(tmpFile).open(context.Background(), true)
+	if err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+	time.AfterFunc(100*time.Millisecond, func() { closeFile(f) })
+
+	withLockTimeout(t, 5*time.Second)
+	if err := AddTask(tmpFile, "Task"); err != nil {
+		t.Errorf("expected lock to be acquired once released, got %v", err)
+	}
+}
+
+func TestLockStatus(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	info, err := LockStatus(tmpFile)
+	if err != nil || info.Locked {
+		t.Fatalf("LockStatus() = %+v, %v; want unlocked", info, err)
+	}
+
+	holdLock(t, tmpFile)
+	info, err = LockStatus(tmpFile)
+	if err != nil {
+		t.Fatalf("LockStatus error: %v", err)
+	}
+	if !info.Locked || info.PID != os.Getpid() {
+		t.Errorf("LockStatus() = %+v, want locked by %d", info, os.Getpid())
+	}
+}
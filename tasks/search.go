@@ -0,0 +1,39 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+)
+
+// SearchTasks returns the tasks ListTasks would return whose description contains
+// every word of query, ignoring case.
+func SearchTasks(filename string, query string, all bool) ([]Task, error) {
+	return New(filename).Search(context.Background(), query, all)
+}
+
+// Search returns the tasks List would return whose description contains every
+// word of query, ignoring case. An empty query matches every task.
+func (c *Client) Search(ctx context.Context, query string, all bool) ([]Task, error) {
+	var matches []Task
+	for task, err := range c.ListSeq(ctx, all) {
+		if err != nil {
+			return nil, err
+		}
+		if task.Matches(query) {
+			matches = append(matches, task)
+		}
+	}
+	return matches, nil
+}
+
+// Matches reports whether the description of the task contains every word of
+// query, ignoring case. An empty query matches every task.
+func (t Task) Matches(query string) bool {
+	description := strings.ToLower(t.Description)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(description, word) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package tasks
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSearchTasks(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	for _, desc := range []string{"Buy groceries", "Water the plants", "Buy plant food"} {
+		if err := AddTask(tmpFile, desc); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	if err := CompleteTask(tmpFile, "3"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		all   bool
+		want  []int
+	}{
+		{"single word", "buy", false, []int{1}},
+		{"completed included with all", "BUY", true, []int{1, 3}},
+		{"every word must match", "buy plant", true, []int{3}},
+		{"substring", "plant", true, []int{2, 3}},
+		{"no match", "laundry", true, []int{}},
+		{"empty query", "", false, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SearchTasks(tmpFile, tt.query, tt.all)
+			if err != nil {
+				t.Fatalf("SearchTasks error: %v", err)
+			}
+			if ids := taskIDs(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("SearchTasks(%q, %v) = %v, want %v", tt.query, tt.all, ids, tt.want)
+			}
+		})
+	}
+}
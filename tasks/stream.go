@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+)
+
+// AllTasks streams every task in the datasource, including the trash, one record at
+// a time rather than reading the whole file into memory.
+func AllTasks(filename string) iter.Seq2[Task, error] {
+	return New(filename).All(context.Background())
+}
+
+// All streams every task, including the trash, one record at a time rather than
+// reading the whole file into memory. The shared lock is held until the iteration
+// stops, so a slow consumer delays writers. Iteration ends after the first error.
+func (c *Client) All(ctx context.Context) iter.Seq2[Task, error] {
+	return c.scanLocked(ctx, c.filename)
+}
+
+// ListTasksSeq streams the tasks ListTasks would return.
+func ListTasksSeq(filename string, all bool) iter.Seq2[Task, error] {
+	return New(filename).ListSeq(context.Background(), all)
+}
+
+// ListSeq streams the tasks List would return.
+func (c *Client) ListSeq(ctx context.Context, all bool) iter.Seq2[Task, error] {
+	return func(yield func(Task, error) bool) {
+		for task, err := range c.All(ctx) {
+			if err == nil && (task.IsDeleted() || (!all && task.IsCompleted)) {
+				continue
+			}
+			if !yield(task, err) {
+				return
+			}
+		}
+	}
+}
+
+// ArchivedTasksSeq streams the tasks ListArchivedTasks would return.
+func ArchivedTasksSeq(filename string) iter.Seq2[Task, error] {
+	return New(filename).ArchivedSeq(context.Background())
+}
+
+// ArchivedSeq streams the tasks Archived would return.
+func (c *Client) ArchivedSeq(ctx context.Context) iter.Seq2[Task, error] {
+	return c.scanLocked(ctx, archivePath(c.filename))
+}
+
+// scanLocked streams the tasks of the data file or its archive at path under the
+// shared lock. A missing archive has no tasks.
+func (c *Client) scanLocked(ctx context.Context, path string) iter.Seq2[Task, error] {
+	return func(yield func(Task, error) bool) {
+		file, err := c.open(ctx, false)
+		if err != nil {
+			yield(Task{}, err)
+			return
+		}
+		stopped := false
+		defer func() {
+			if err := closeFile(file); err != nil && !stopped {
+				yield(Task{}, fmt.Errorf("failed to close file: %w", err))
+			}
+		}()
+
+		r := io.Reader(file)
+		if path != c.filename {
+			f, err := os.Open(path)
+			if errors.Is(err, os.ErrNotExist) {
+				return
+			}
+			if err != nil {
+				stopped = !yield(Task{}, fmt.Errorf("failed to read archive: %w", err))
+				return
+			}
+			defer f.Close()
+			r = f
+		}
+
+		for task, err := range scanFile(path, bufio.NewReader(r)) {
+			if !yield(task, err) {
+				stopped = true
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// scanFile streams the tasks of the file at path read from r. Event logs have to be
+// replayed in full before their tasks are known.
+func scanFile(path string, r io.Reader) iter.Seq2[Task, error] {
+	if !isEventLog(path) {
+		return scanTasks(r)
+	}
+	return func(yield func(Task, error) bool) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			yield(Task{}, fmt.Errorf("failed to read file: %w", err))
+			return
+		}
+		tasks, err := replayEventLog(data)
+		if err != nil {
+			yield(Task{}, err)
+			return
+		}
+		for _, task := range tasks {
+			if !yield(task, nil) {
+				return
+			}
+		}
+	}
+}
+
+// scanTasks parses a data file read from r record by record. An empty file has no tasks.
+func scanTasks(r io.Reader) iter.Seq2[Task, error] {
+	return func(yield func(Task, error) bool) {
+		csvReader := newCSVReader(r)
+		csvReader.ReuseRecord = true
+		header, err := csvReader.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield(Task{}, err)
+			return
+		}
+		cols, err := parseHeader(header)
+		if err != nil {
+			yield(Task{}, err)
+			return
+		}
+
+		for {
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(Task{}, fmt.Errorf("malformed record: %w", err))
+				return
+			}
+			task, err := parseRecord(cols, record)
+			if !yield(task, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ExportCSV streams every task, including the trash, to w in the format of the data
+// file, so the output can be used as a data file itself.
+func ExportCSV(filename string, w io.Writer) error {
+	return New(filename).ExportCSV(context.Background(), w)
+}
+
+// ExportCSV streams every task, including the trash, to w in the format of the
+// data file, so the output can be used as a data file itself.
+func (c *Client) ExportCSV(ctx context.Context, w io.Writer) error {
+	if _, err := io.WriteString(w, csvHeader); err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(w)
+	for task, err := range c.All(ctx) {
+		if err != nil {
+			return err
+		}
+		if err := csvWriter.Write(taskToRecord(task)); err != nil {
+			return fmt.Errorf("failed to write task: %w", err)
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,151 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListTasksSeq(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	content := csvHeader +
+		"1,Open,2025-05-12T10:00:00Z,false\n" +
+		"2,Done,2025-05-12T10:00:00Z,true\n" +
+		"3,Trashed,2025-05-12T10:00:00Z,false,2025-05-13T10:00:00Z\n" +
+		"4,Open too,2025-05-12T10:00:00Z,false\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	collect := func(seq func(func(Task, error) bool)) []int {
+		var ids []int
+		for task, err := range seq {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+	if got := collect(AllTasks(tmpFile)); len(got) != 4 {
+		t.Errorf("AllTasks() = %v, want every task", got)
+	}
+	if got := collect(ListTasksSeq(tmpFile, false)); len(got) != 2 || got[0] != 1 || got[1] != 4 {
+		t.Errorf("ListTasksSeq(false) = %v, want [1 4]", got)
+	}
+	if got := collect(ListTasksSeq(tmpFile, true)); len(got) != 3 {
+		t.Errorf("ListTasksSeq(true) = %v, want [1 2 4]", got)
+	}
+
+	t.Run("stopping early releases the lock", func(t *testing.T) {
+		withLockTimeout(t, NoWait)
+		for range AllTasks(tmpFile) {
+			break
+		}
+		if err := CompleteTask(tmpFile, "1"); err != nil {
+			t.Errorf("CompleteTask after break: %v", err)
+		}
+	})
+
+	t.Run("errors end the iteration", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.csv")
+		if err := os.WriteFile(bad, []byte(csvHeader+"1,Open,2025-05-12T10:00:00Z,false\nx,Bad,2025-05-12T10:00:00Z,false\n2,Never,2025-05-12T10:00:00Z,false\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		var errs []error
+		for task, err := range AllTasks(bad) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			ids = append(ids, task.ID)
+		}
+		if len(ids) != 1 || len(errs) != 1 || !strings.Contains(errs[0].Error(), "failed to parse ID") {
+			t.Errorf("got tasks %v and errors %v, want task 1 then one parse error", ids, errs)
+		}
+	})
+}
+
+func TestExportCSV(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	content := csvHeader +
+		"1,Open,2025-05-12T10:00:00Z,false,,,6f1c9c52-8d4e-4b71-9a0e-2c7d1f3b5a64\n" +
+		"2,\"Trashed, with comma\",2025-05-12T10:00:00Z,false,2025-05-13T10:00:00Z,,d2a4e8f0-1b3c-4e5d-8f6a-7b9c0d1e2f3a\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportCSV(tmpFile, &buf); err != nil {
+		t.Fatalf("ExportCSV error: %v", err)
+	}
+	if buf.String() != content {
+		t.Errorf("ExportCSV() = %q, want the data file %q", buf.String(), content)
+	}
+}
+
+// writeBenchmarkFile writes a data file of n tasks, every tenth of them completed.
+func writeBenchmarkFile(b *testing.B, n int) string {
+	b.Helper()
+	tmpFile := filepath.Join(b.TempDir(), "tasks.csv")
+	var buf bytes.Buffer
+	buf.WriteString(csvHeader)
+	created := time.Date(2025, 5, 12, 10, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&buf, "%d,Benchmark task number %d,%s,%t,,,\n", i, i, created, i%10 == 0)
+	}
+	if err := os.WriteFile(tmpFile, buf.Bytes(), 0644); err != nil {
+		b.Fatalf("failed to write benchmark file: %v", err)
+	}
+	return tmpFile
+}
+
+// The benchmarks compare listing by loading every task (as ListTasks does) with
+// streaming them; run with -benchmem to see the difference in memory.
+
+func BenchmarkListTasks(b *testing.B) {
+	tmpFile := writeBenchmarkFile(b, 100_000)
+	b.ReportAllocs()
+	for b.Loop() {
+		list, err := ListTasks(tmpFile, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(list) != 90_000 {
+			b.Fatalf("listed %d tasks", len(list))
+		}
+	}
+}
+
+func BenchmarkListTasksSeq(b *testing.B) {
+	tmpFile := writeBenchmarkFile(b, 100_000)
+	b.ReportAllocs()
+	for b.Loop() {
+		n := 0
+		for _, err := range ListTasksSeq(tmpFile, false) {
+			if err != nil {
+				b.Fatal(err)
+			}
+			n++
+		}
+		if n != 90_000 {
+			b.Fatalf("listed %d tasks", n)
+		}
+	}
+}
+
+func BenchmarkReadAllTasks(b *testing.B) {
+	tmpFile := writeBenchmarkFile(b, 100_000)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := New(tmpFile).load(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,23 +2,39 @@ package tasks
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strconv"
-	"syscall"
 	"time"
 )
 
-const csvHeader = "ID,Description,CreatedAt,IsComplete\n"
+// ErrTaskNotFound is returned when no task matches the requested ID.
+var ErrTaskNotFound = errors.New("task not found")
 
 type Task struct {
-	ID          int
-	Description string
-	CreatedAt   time.Time
-	IsCompleted bool
+	ID          int       `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	IsCompleted bool      `json:"is_completed"`
+	// DeletedAt is set when the task has been moved to the trash.
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
+	// CompletedAt is set when the task is marked as completed.
+	CompletedAt time.Time `json:"completed_at,omitzero"`
+	// UUID identifies the task permanently, unlike ID which is short but only unique per file.
+	UUID string `json:"uuid,omitempty"`
+	// Extra holds the values of columns in the data file this version does not know,
+	// such as one added by hand, so rewriting the file keeps them.
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// IsDeleted reports whether the task is in the trash.
+func (t Task) IsDeleted() bool {
+	return !t.DeletedAt.IsZero()
 }
 
 func (t Task) String() string {
@@ -26,313 +42,528 @@ func (t Task) String() string {
 }
 
 // ensureDataSource checks if the file exists, and if not, creates it with the correct header.
+// It is called with at least a shared lock held, so concurrent readers may race to create
+// the file; writing it via rename means they never observe a partial header.
 func ensureDataSource(filepath string) error {
 	if _, err := os.Stat(filepath); errors.Is(err, os.ErrNotExist) {
-		file, err := os.OpenFile(filepath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		err := replaceFile(filepath, func(f *os.File) error {
+			// An empty event log has no tasks
+			if isEventLog(filepath) {
+				return nil
+			}
+			_, err := f.WriteString(csvHeader)
+			return err
+		})
 		if err != nil {
 			return fmt.Errorf("failed to create datasource: %w", err)
 		}
-		if _, err = file.WriteString(csvHeader); err != nil {
-			file.Close()
-			return fmt.Errorf("failed to write header: %w", err)
-		}
-		file.Close()
 	}
 	return nil
 }
 
-func loadFile(filepath string) (*os.File, error) {
-	if err := ensureDataSource(filepath); err != nil {
-		return nil, err
+// replaceTasks atomically replaces the contents of the locked data file with the given
+// tasks, taking a backup of the previous contents first. Files written in an older
+// format are upgraded to the current one.
+func replaceTasks(file *lockedFile, tasks []Task) error {
+	if err := checkFormat(file.Name()); err != nil {
+		return err
 	}
-	f, err := os.OpenFile(filepath, os.O_RDWR|os.O_CREATE, os.ModePerm)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+	if err := file.client.autoBackup(); err != nil {
+		return err
 	}
-	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
-		_ = f.Close()
-		return nil, err
-	}
-	return f, nil
+	return replaceFile(file.Name(), func(f *os.File) error {
+		return encodeTasks(file.Name(), f, tasks)
+	})
 }
 
-func closeFile(f *os.File) error {
-	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN); err != nil {
-		return fmt.Errorf("failed to unlock file: %w", err)
+func readTasksFromCSVData(data []byte) ([]Task, error) {
+	tasks := []Task{}
+	for task, err := range scanTasks(bytes.NewReader(data)) {
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
 	}
-	return f.Close()
+	return tasks, nil
 }
 
-func readTasksFromCSVData(data []byte) ([]Task, error) {
-	if len(data) == 0 {
-		return []Task{}, nil
+// parseRecord converts one CSV record of the data file into a Task, looking up
+// fields by the columns of the file's header.
+func parseRecord(cols columns, record []string) (Task, error) {
+	if len(record) < cols.width() {
+		return Task{}, fmt.Errorf("malformed record: %w: expected at least %d fields but got %d in record: %v", csv.ErrFieldCount, cols.width(), len(record), record)
 	}
 
-	csvReader := csv.NewReader(bytes.NewReader(data))
-	_, err := csvReader.Read()
+	id, err := strconv.Atoi(cols.field(record, "ID"))
 	if err != nil {
-		return nil, err
+		return Task{}, fmt.Errorf("failed to parse ID: %w", err)
 	}
 
-	records, err := csvReader.ReadAll()
+	createdAt, err := time.Parse(time.RFC3339, cols.field(record, "CreatedAt"))
 	if err != nil {
-		return nil, fmt.Errorf("malformed record: %w", err)
+		return Task{}, fmt.Errorf("failed to parse CreatedAt: %w", err)
 	}
 
-	var tasks []Task
-	for _, record := range records {
-		if len(record) < 4 {
-			return nil, fmt.Errorf("malformed record: expected at least 4 fields but got %d in record: %v", len(record), record)
-		}
-
-		id, err := strconv.Atoi(record[0])
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse ID: %w", err)
+	completed, err := strconv.ParseBool(cols.field(record, "IsComplete"))
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to parse IsCompleted: %w", err)
+	}
+
+	task := Task{
+		ID:          id,
+		Description: cols.field(record, "Description"),
+		CreatedAt:   createdAt,
+		IsCompleted: completed,
+		UUID:        cols.field(record, "UUID"),
+	}
+	for name := range cols {
+		if !slices.Contains(csvColumns, name) {
+			if task.Extra == nil {
+				task.Extra = make(map[string]string)
+			}
+			task.Extra[name] = cols.field(record, name)
 		}
+	}
 
-		createdAt, err := time.Parse(time.RFC3339, record[2])
+	// Files written before the trash existed have no DeletedAt column
+	if deletedAt := cols.field(record, "DeletedAt"); deletedAt != "" {
+		task.DeletedAt, err = time.Parse(time.RFC3339, deletedAt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse CreatedAt: %w", err)
+			return Task{}, fmt.Errorf("failed to parse DeletedAt: %w", err)
 		}
+	}
 
-		completed, err := strconv.ParseBool(record[3])
+	if completedAt := cols.field(record, "CompletedAt"); completedAt != "" {
+		task.CompletedAt, err = time.Parse(time.RFC3339, completedAt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse IsCompleted: %w", err)
-		}
-
-		task := Task{
-			ID:          id,
-			Description: record[1],
-			CreatedAt:   createdAt,
-			IsCompleted: completed,
+			return Task{}, fmt.Errorf("failed to parse CompletedAt: %w", err)
 		}
-		tasks = append(tasks, task)
 	}
-	return tasks, nil
+	return task, nil
 }
 
-// AddTask appends a new task to the datasource (CSV file).
-func AddTask(filename string, description string) error {
-	file, err := loadFile(filename)
-	if err != nil {
-		return fmt.Errorf("failed to open datasource for appending: %w", err)
+// writeTasksToFile replaces the contents of file with the given tasks. Columns
+// only found in their Extra follow the known ones, sorted by name.
+func writeTasksToFile(file *os.File, tasks []Task) error {
+	// Truncate file before writing updated tasks
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to start of file: %w", err)
 	}
-	defer func() {
-		if err := closeFile(file); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
-		}
-	}()
-
-	// Move to end of file for appending
-	if _, err := file.Seek(0, io.SeekEnd); err != nil {
-		return fmt.Errorf("failed to seek to end of file: %w", err)
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate file: %w", err)
 	}
 
-	// Determine next ID
-	file.Seek(0, io.SeekStart)
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return fmt.Errorf("failed to read file for ID: %w", err)
-	}
-	tasks, err := readTasksFromCSVData(data)
-	if err != nil {
-		return fmt.Errorf("failed to parse tasks for ID: %w", err)
+	// Write header
+	extra := extraColumns(tasks)
+	header := csvHeader
+	if len(extra) > 0 {
+		header = fmt.Sprintf("%s%d\n", formatMarker, formatVersion)
 	}
-	nextID := 1
-	if len(tasks) > 0 {
-		nextID = tasks[len(tasks)-1].ID + 1
+	if _, err := file.WriteString(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
 	}
 
 	csvWriter := csv.NewWriter(file)
-	record := []string{
-		strconv.Itoa(nextID),
-		description,
-		time.Now().Format(time.RFC3339),
-		"false",
+	if len(extra) > 0 {
+		if err := csvWriter.Write(slices.Concat(csvColumns, extra)); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
 	}
-	if err := csvWriter.Write(record); err != nil {
-		return fmt.Errorf("failed to write task: %w", err)
+	for _, task := range tasks {
+		record := taskToRecord(task)
+		for _, name := range extra {
+			record = append(record, task.Extra[name])
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write task: %w", err)
+		}
 	}
 	csvWriter.Flush()
-	return csvWriter.Error()
-}
-
-func ListTasks(filename string, all bool) ([]Task, error) {
-	// Load and syslock file (this will create the file if it doesn't exist)
-	file, err := loadFile(filename)
-	if err != nil {
-		return nil, err
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("failed to flush csv writer: %w", err)
 	}
+	return nil
+}
 
-	// Defer file closing
-	defer func() {
-		if err := closeFile(file); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
+// extraColumns returns the names of the unknown columns kept by any of tasks, sorted.
+func extraColumns(tasks []Task) []string {
+	var names []string
+	for _, task := range tasks {
+		for name := range task.Extra {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
 		}
-	}()
-
-	// Read data from file
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return nil, err
-	}
-
-	// Read tasks from CSV Data
-	tasks, err := readTasksFromCSVData(data)
-	if err != nil {
-		return nil, err
 	}
+	slices.Sort(names)
+	return names
+}
 
-	// If all flag
-	if all {
-		return tasks, nil
+func taskToRecord(task Task) []string {
+	return []string{
+		strconv.Itoa(task.ID),
+		task.Description,
+		task.CreatedAt.Format(time.RFC3339),
+		strconv.FormatBool(task.IsCompleted),
+		formatOptionalTime(task.DeletedAt),
+		formatOptionalTime(task.CompletedAt),
+		task.UUID,
 	}
+}
 
-	// Reparate uncompleted tasks
-	uncompletedTasks := make([]Task, 0, len(tasks))
-	for _, task := range tasks {
-		if !task.IsCompleted {
-			uncompletedTasks = append(uncompletedTasks, task)
-		}
+// formatOptionalTime formats t as RFC3339, or as an empty field when t is unset.
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
-
-	// Return uncompleted list
-	return uncompletedTasks, nil
+	return t.Format(time.RFC3339)
 }
 
-func CompleteTask(filename string, taskID string) error {
+// update loads every task under the datasource lock, lets fn modify them and
+// writes the result back, recording the difference in the journal as op.
+func (c *Client) update(ctx context.Context, op string, fn func(tasks []Task) ([]Task, error)) (err error) {
 	// Load and syslock file
-	file, err := loadFile(filename)
+	file, err := c.open(ctx, true)
 	if err != nil {
 		return fmt.Errorf("failed to open datasource for updating: %w", err)
 	}
-	defer func() {
-		if err := closeFile(file); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
-		}
-	}()
+	defer closeLocked(file, &err)
 
 	// Read data from file
 	data, err := io.ReadAll(file)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
-	tasks, err := readTasksFromCSVData(data)
+	tasks, err := decodeTasks(c.filename, data)
 	if err != nil {
 		return fmt.Errorf("failed to parse tasks: %w", err)
 	}
-	id, err := strconv.Atoi(taskID)
+
+	// Tasks from older files get their UUID on the first write, without a journal entry
+	assignMissingUUIDs(tasks)
+	before := slices.Clone(tasks)
+	tasks, err = fn(tasks)
 	if err != nil {
-		return fmt.Errorf("failed to parse task ID: %w", err)
+		return err
 	}
-	for i, task := range tasks {
-		if task.ID == id {
-			tasks[i].IsCompleted = true
-			tasks[i].CreatedAt = time.Now() // Update the CreatedAt to now
-			break
-		}
+
+	// Leave the file untouched when nothing changed
+	changes := diffTasks(before, tasks)
+	if len(changes) == 0 {
+		return nil
 	}
-	// Truncate file before writing updated tasks
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek to start of file: %w", err)
+	if err := saveChanges(file, data, tasks, changes); err != nil {
+		return err
 	}
-	if err := file.Truncate(0); err != nil {
-		return fmt.Errorf("failed to truncate file: %w", err)
+	return recordChange(c.filename, op, changes)
+}
+
+// AddTask appends a new task to the datasource (CSV file).
+func AddTask(filename string, description string) error {
+	_, err := New(filename).Add(context.Background(), description)
+	return err
+}
+
+// Add appends a new task and returns it. When the ID counter is up to date the
+// task is appended without reading the existing tasks.
+func (c *Client) Add(ctx context.Context, description string) (_ Task, err error) {
+	file, err := c.open(ctx, true)
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to open datasource for appending: %w", err)
 	}
-	// Write header
-	if _, err := file.WriteString(csvHeader); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+	defer closeLocked(file, &err)
+
+	task := Task{
+		Description: description,
+		CreatedAt:   time.Now().Truncate(time.Second),
+		UUID:        newUUID(),
 	}
-	csvWriter := csv.NewWriter(file)
-	for _, task := range tasks {
-		record := []string{
-			strconv.Itoa(task.ID),
-			task.Description,
-			task.CreatedAt.Format(time.RFC3339),
-			strconv.FormatBool(task.IsCompleted),
+
+	current, err := hasCurrentHeader(file)
+	if err != nil {
+		return Task{}, err
+	}
+	if current {
+		task.ID, err = quickNextTaskID(c.filename)
+		if err != nil {
+			return Task{}, err
 		}
-		if err := csvWriter.Write(record); err != nil {
+	}
+
+	// Determine next ID from every task when the counter can't be trusted
+	if task.ID == 0 {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return Task{}, fmt.Errorf("failed to read file for ID: %w", err)
+		}
+		tasks, err := decodeTasks(c.filename, data)
+		if err != nil {
+			return Task{}, fmt.Errorf("failed to parse tasks for ID: %w", err)
+		}
+		task.ID, err = nextTaskID(c.filename, tasks)
+		if err != nil {
+			return Task{}, err
+		}
+
+		// Files in an older format are upgraded so every record has the current columns
+		if !current {
+			assignMissingUUIDs(tasks)
+			if err := replaceTasks(file, append(tasks, task)); err != nil {
+				return Task{}, err
+			}
+			if err := saveTaskID(c.filename, task.ID); err != nil {
+				return Task{}, err
+			}
+			return task, recordChange(c.filename, "add", []Change{{ID: task.ID, After: &task}})
+		}
+	}
+
+	change := Change{ID: task.ID, After: &task}
+	if isEventLog(c.filename) {
+		err = appendEvents(file, changeEvents([]Change{change}))
+	} else {
+		err = appendRecord(file, taskToRecord(task))
+	}
+	if err != nil {
+		return Task{}, err
+	}
+	if err := saveTaskID(c.filename, task.ID); err != nil {
+		return Task{}, err
+	}
+	return task, recordChange(c.filename, "add", []Change{change})
+}
+
+// hasCurrentHeader reports whether the data file starts with the header this
+// version writes, reading only the header. Event logs have no header.
+func hasCurrentHeader(file *lockedFile) (bool, error) {
+	if isEventLog(file.Name()) {
+		return true, nil
+	}
+	buf := make([]byte, len(csvHeader))
+	_, err := file.ReadAt(buf, 0)
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read header: %w", err)
+	}
+	return isCurrentFormat(buf), nil
+}
+
+// appendRecord writes record at the end of the data file and syncs it.
+func appendRecord(file *lockedFile, record []string) error {
+	end, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek to end of file: %w", err)
+	}
+	// A hand-edited file may lack the final newline
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, end-1); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if last[0] != '\n' {
+		if _, err := file.WriteString("\n"); err != nil {
 			return fmt.Errorf("failed to write task: %w", err)
 		}
 	}
+
+	csvWriter := csv.NewWriter(file)
+	if err := csvWriter.Write(record); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
 	csvWriter.Flush()
 	if err := csvWriter.Error(); err != nil {
-		return fmt.Errorf("failed to flush csv writer: %w", err)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
 	}
-	// If we reach here, the task was successfully marked as completed
 	return nil
 }
 
-func DeleteTask(filename string, taskID string) error {
-	// Load and syslock file
-	file, err := loadFile(filename)
+// load reads every task in the datasource under lock, including the trash.
+func (c *Client) load(ctx context.Context) (_ []Task, err error) {
+	// Load and share-lock file (this will create the file if it doesn't exist)
+	file, err := c.open(ctx, false)
 	if err != nil {
-		return fmt.Errorf("failed to open datasource for deleting: %w", err)
+		return nil, err
 	}
+	defer closeLocked(file, &err)
 
-	defer func() {
-		if err := closeFile(file); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to close file: %v\n", err)
-		}
-	}()
-
+	// Read data from file
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return nil, err
 	}
 
-	tasks, err := readTasksFromCSVData(data)
+	// Read tasks from CSV Data, or replay the event log
+	return decodeTasks(c.filename, data)
+}
+
+// ListTasks returns the tasks that are not in the trash, leaving out completed ones unless all is set.
+func ListTasks(filename string, all bool) ([]Task, error) {
+	return New(filename).List(context.Background(), all)
+}
+
+// List returns the tasks that are not in the trash, leaving out completed ones unless all is set.
+func (c *Client) List(ctx context.Context, all bool) ([]Task, error) {
+	var visibleTasks []Task
+	for task, err := range c.ListSeq(ctx, all) {
+		if err != nil {
+			return nil, err
+		}
+		visibleTasks = append(visibleTasks, task)
+	}
+	return visibleTasks, nil
+}
+
+// GetTask returns the task with the given ID or UUID, or ErrTaskNotFound if it does not exist.
+// Tasks in the trash and in the archive are returned as well.
+func GetTask(filename string, taskID string) (Task, error) {
+	ref, err := parseTaskRef(taskID)
 	if err != nil {
-		return fmt.Errorf("failed to parse tasks: %w", err)
+		return Task{}, err
 	}
+	return New(filename).get(context.Background(), ref)
+}
+
+// Get returns the task with the given ID, or ErrTaskNotFound if it does not exist.
+// Tasks in the trash and in the archive are returned as well.
+func (c *Client) Get(ctx context.Context, id int) (Task, error) {
+	return c.get(ctx, taskRef{id: id})
+}
+
+// GetByUUID is like Get, but finds the task by its UUID.
+func (c *Client) GetByUUID(ctx context.Context, uuid string) (Task, error) {
+	return c.get(ctx, taskRef{uuid: uuid})
+}
 
-	id, err := strconv.Atoi(taskID)
+func (c *Client) get(ctx context.Context, ref taskRef) (Task, error) {
+	tasks, err := c.load(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to parse task ID: %w", err)
+		return Task{}, err
 	}
 
-	for i, task := range tasks {
-		if task.ID == id {
-			// Remove the task from the slice
-			tasks = append(tasks[:i], tasks[i+1:]...)
-			break
+	for _, task := range tasks {
+		if ref.matches(task) {
+			return task, nil
 		}
 	}
 
-	// Truncate file before writing updated tasks
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek to start of file: %w", err)
+	archived, err := c.Archived(ctx)
+	if err != nil {
+		return Task{}, err
 	}
-
-	if err := file.Truncate(0); err != nil {
-		return fmt.Errorf("failed to truncate file: %w", err)
+	for _, task := range archived {
+		if ref.matches(task) {
+			return task, nil
+		}
 	}
+	return Task{}, fmt.Errorf("task %s: %w", ref, ErrTaskNotFound)
+}
 
-	// Write header
-	if _, err := file.WriteString(csvHeader); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+// CompleteTask marks the tasks with the given IDs or UUIDs as completed. Tasks that
+// exist are completed even when others do not; those are reported with ErrTaskNotFound.
+func CompleteTask(filename string, taskIDs ...string) error {
+	refs, err := parseTaskRefs(taskIDs)
+	if err != nil {
+		return err
 	}
+	return New(filename).complete(context.Background(), refs)
+}
 
-	csvWriter := csv.NewWriter(file)
+// Complete marks the tasks with the given IDs as completed. Tasks that exist are
+// completed even when others do not, or are in the trash; those are reported with
+// ErrTaskNotFound.
+func (c *Client) Complete(ctx context.Context, ids ...int) error {
+	return c.complete(ctx, idRefs(ids))
+}
 
-	for _, task := range tasks {
-		record := []string{
-			strconv.Itoa(task.ID),
-			task.Description,
-			task.CreatedAt.Format(time.RFC3339),
-			strconv.FormatBool(task.IsCompleted),
-		}
-		if err := csvWriter.Write(record); err != nil {
-			return fmt.Errorf("failed to write task: %w", err)
+func (c *Client) complete(ctx context.Context, refs []taskRef) error {
+	now := time.Now().Truncate(time.Second)
+	return c.updateEach(ctx, "complete", refs, isCurrent, func(task *Task) {
+		// Completing again keeps when the task was first completed
+		if !task.IsCompleted {
+			task.IsCompleted = true
+			task.CompletedAt = now
 		}
+	})
+}
+
+// ReopenTask marks completed tasks as not completed again. Tasks that exist are
+// reopened even when others do not; those are reported with ErrTaskNotFound.
+func ReopenTask(filename string, taskIDs ...string) error {
+	refs, err := parseTaskRefs(taskIDs)
+	if err != nil {
+		return err
 	}
+	return New(filename).reopen(context.Background(), refs)
+}
 
-	csvWriter.Flush()
+// Reopen marks completed tasks as not completed again. Tasks that exist are
+// reopened even when others do not, or are in the trash; those are reported with
+// ErrTaskNotFound.
+func (c *Client) Reopen(ctx context.Context, ids ...int) error {
+	return c.reopen(ctx, idRefs(ids))
+}
 
-	if err := csvWriter.Error(); err != nil {
-		return fmt.Errorf("failed to flush csv writer: %w", err)
+func (c *Client) reopen(ctx context.Context, refs []taskRef) error {
+	return c.updateEach(ctx, "reopen", refs, isCurrent, func(task *Task) {
+		task.IsCompleted = false
+		task.CompletedAt = time.Time{}
+	})
+}
+
+// DeleteTask moves tasks to the trash. They stay recoverable with RestoreTask
+// until they are permanently removed by PurgeTasks. Tasks that exist are deleted
+// even when others do not, or are already in the trash; those are reported with
+// ErrTaskNotFound.
+func DeleteTask(filename string, taskIDs ...string) error {
+	refs, err := parseTaskRefs(taskIDs)
+	if err != nil {
+		return err
 	}
+	return New(filename).delete(context.Background(), refs)
+}
 
-	// If we reach here, the task was successfully deleted
-	return nil
+// Delete moves tasks to the trash. They stay recoverable with Restore until they
+// are permanently removed by Purge. Tasks that exist are deleted even when others
+// do not, or are already in the trash; those are reported with ErrTaskNotFound.
+func (c *Client) Delete(ctx context.Context, ids ...int) error {
+	return c.delete(ctx, idRefs(ids))
+}
+
+func (c *Client) delete(ctx context.Context, refs []taskRef) error {
+	now := time.Now().Truncate(time.Second)
+	return c.updateEach(ctx, "delete", refs, isCurrent, func(task *Task) {
+		task.DeletedAt = now
+	})
+}
+
+// isCurrent reports whether a task is outside the trash.
+func isCurrent(task Task) bool {
+	return !task.IsDeleted()
+}
+
+// updateEach calls fn on each task named by refs that has the given state, in a
+// single update recorded as op. Tasks that are found are changed even when others
+// are not; the missing ones are reported with ErrTaskNotFound afterwards.
+func (c *Client) updateEach(ctx context.Context, op string, refs []taskRef, state func(Task) bool, fn func(*Task)) error {
+	var missing []error
+	err := c.update(ctx, op, func(tasks []Task) ([]Task, error) {
+		missing = nil
+		for _, ref := range refs {
+			i := slices.IndexFunc(tasks, func(t Task) bool { return ref.matches(t) && state(t) })
+			if i < 0 {
+				missing = append(missing, fmt.Errorf("task %s: %w", ref, ErrTaskNotFound))
+				continue
+			}
+			fn(&tasks[i])
+		}
+		return tasks, nil
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(missing...)
 }
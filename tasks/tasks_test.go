@@ -1,6 +1,9 @@
 package tasks
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -101,15 +104,14 @@ func TestTaskString(t *testing.T) {
 }
 
 func TestLoadAndCloseFile(t *testing.T) {
-	tmpFile := filepath.Join(os.TempDir(), "test_loadfile.csv")
-	defer os.Remove(tmpFile)
+	tmpFile := filepath.Join(t.TempDir(), "test_loadfile.csv")
 
-	f, err := loadFile(tmpFile)
+	f, err := New(tmpFile).open(context.Background(), true)
 	if err != nil {
-		t.Fatalf("loadFile() error = %v", err)
+		t.Fatalf("open() error = %v", err)
 	}
 	if f == nil {
-		t.Fatal("loadFile() returned nil file")
+		t.Fatal("open() returned nil file")
 	}
 
 	err = closeFile(f)
@@ -119,8 +121,7 @@ func TestLoadAndCloseFile(t *testing.T) {
 }
 
 func TestListTasks(t *testing.T) {
-	tmpFile := filepath.Join(os.TempDir(), "test_listtasks.csv")
-	defer os.Remove(tmpFile)
+	tmpFile := filepath.Join(t.TempDir(), "test_listtasks.csv")
 
 	content := "ID,Description,CreatedAt,IsComplete\n1,Task1,2025-05-12T10:00:00Z,true\n2,Task2,2025-05-12T11:00:00Z,false\n"
 	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
@@ -149,9 +150,7 @@ func TestListTasks(t *testing.T) {
 }
 
 func TestAddTask(t *testing.T) {
-	tmpDir := os.TempDir()
-	tmpFile := filepath.Join(tmpDir, "test_addtask.csv")
-	defer os.Remove(tmpFile)
+	tmpFile := filepath.Join(t.TempDir(), "test_addtask.csv")
 
 	testCases := []struct {
 		name        string
@@ -215,9 +214,9 @@ func TestAddTask(t *testing.T) {
 				if len(lines) < 2 {
 					t.Fatalf("expected at least 2 lines (header + task), got %d", len(lines))
 				}
-				// Check header
-				if lines[0] != "ID,Description,CreatedAt,IsComplete" {
-					t.Errorf("header: got %q, want %q", lines[0], "ID,Description,CreatedAt,IsComplete")
+				// Check format marker and header
+				if !strings.HasPrefix(string(data), csvHeader) {
+					t.Errorf("header: got %q, want %q", lines[:2], csvHeader)
 				}
 				// Check that a line contains the description
 				found := false
@@ -241,7 +240,7 @@ func readAllTasks(t *testing.T, filename string) []Task {
 	if err != nil {
 		t.Fatalf("failed to read file: %v", err)
 	}
-	tasks, err := readTasksFromCSVData(data)
+	tasks, err := decodeTasks(filename, data)
 	if err != nil {
 		t.Fatalf("failed to parse tasks: %v", err)
 	}
@@ -249,8 +248,7 @@ func readAllTasks(t *testing.T, filename string) []Task {
 }
 
 func TestCompleteTask(t *testing.T) {
-	tmpFile := filepath.Join(os.TempDir(), "test_completetask.csv")
-	defer os.Remove(tmpFile)
+	tmpFile := filepath.Join(t.TempDir(), "test_completetask.csv")
 
 	t.Run("completes a single task", func(t *testing.T) {
 		content := csvHeader + "1,Task1,2025-05-12T10:00:00Z,false\n"
@@ -270,14 +268,28 @@ func TestCompleteTask(t *testing.T) {
 		}
 	})
 
+	t.Run("completing again keeps the completion time", func(t *testing.T) {
+		content := csvHeader + "1,Task1,2025-05-12T10:00:00Z,true,,2025-05-13T10:00:00Z\n"
+		if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write temp file: %v", err)
+		}
+		if err := CompleteTask(tmpFile, "1"); err != nil {
+			t.Fatalf("CompleteTask error: %v", err)
+		}
+		data, err := os.ReadFile(tmpFile)
+		if err != nil || string(data) != content {
+			t.Errorf("expected the file to be left alone, got %q, %v", data, err)
+		}
+	})
+
 	t.Run("does not complete non-existent task", func(t *testing.T) {
 		content := csvHeader + "1,Task1,2025-05-12T10:00:00Z,false\n"
 		if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
 			t.Fatalf("failed to write temp file: %v", err)
 		}
 		err := CompleteTask(tmpFile, "2")
-		if err != nil {
-			t.Fatalf("CompleteTask error: %v", err)
+		if !errors.Is(err, ErrTaskNotFound) {
+			t.Errorf("expected ErrTaskNotFound, got: %v", err)
 		}
 		tasks := readAllTasks(t, tmpFile)
 		if tasks[0].IsCompleted {
@@ -317,14 +329,143 @@ func TestCompleteTask(t *testing.T) {
 	})
 
 	t.Run("file does not exist returns error", func(t *testing.T) {
-		badFile := filepath.Join(os.TempDir(), "does_not_exist.csv")
-		os.Remove(badFile)
+		badFile := filepath.Join(t.TempDir(), "does_not_exist.csv")
 		err := CompleteTask(badFile, "1")
+		// ensureDataSource creates the file, which holds no task to complete
+		if !errors.Is(err, ErrTaskNotFound) {
+			t.Errorf("expected ErrTaskNotFound, got: %v", err)
+		}
+	})
+}
+
+func TestGetTask(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	content := csvHeader +
+		"1,Task1,2025-05-12T10:00:00Z,true\n" +
+		"2,Task2,2025-05-12T11:00:00Z,false\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	t.Run("returns the requested task", func(t *testing.T) {
+		task, err := GetTask(tmpFile, "1")
 		if err != nil {
-			// Should not error, as ensureDataSource creates the file
-			if !strings.Contains(err.Error(), "failed to parse tasks") {
-				t.Errorf("unexpected error: %v", err)
-			}
+			t.Fatalf("GetTask error: %v", err)
+		}
+		want := Task{ID: 1, Description: "Task1", CreatedAt: time.Date(2025, 5, 12, 10, 0, 0, 0, time.UTC), IsCompleted: true}
+		if !reflect.DeepEqual(task, want) {
+			t.Errorf("GetTask() = %#v, want %#v", task, want)
+		}
+	})
+
+	t.Run("unknown ID returns ErrTaskNotFound", func(t *testing.T) {
+		_, err := GetTask(tmpFile, "3")
+		if !errors.Is(err, ErrTaskNotFound) {
+			t.Errorf("expected ErrTaskNotFound, got: %v", err)
 		}
 	})
+
+	t.Run("invalid task ID returns error", func(t *testing.T) {
+		_, err := GetTask(tmpFile, "notanumber")
+		if err == nil || !strings.Contains(err.Error(), "failed to parse task ID") {
+			t.Errorf("expected error for invalid task ID, got: %v", err)
+		}
+	})
+}
+
+func TestReopenTask(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	t.Run("reopens a completed task", func(t *testing.T) {
+		content := csvHeader + "1,Task1,2025-05-12T10:00:00Z,true,,2025-05-13T10:00:00Z\n"
+		if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write temp file: %v", err)
+		}
+		if err := ReopenTask(tmpFile, "1"); err != nil {
+			t.Fatalf("ReopenTask error: %v", err)
+		}
+		tasks := readAllTasks(t, tmpFile)
+		if tasks[0].IsCompleted || !tasks[0].CompletedAt.IsZero() {
+			t.Errorf("expected task to be reopened, got %+v", tasks[0])
+		}
+		events, err := TaskHistory(tmpFile, "1")
+		if err != nil {
+			t.Fatalf("TaskHistory error: %v", err)
+		}
+		if len(events) == 0 || events[len(events)-1].Op != "reopen" {
+			t.Errorf("expected reopen to be recorded, got %+v", events)
+		}
+	})
+
+	t.Run("does not reopen non-existent task", func(t *testing.T) {
+		content := csvHeader + "1,Task1,2025-05-12T10:00:00Z,true,,2025-05-13T10:00:00Z\n"
+		if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write temp file: %v", err)
+		}
+		if err := ReopenTask(tmpFile, "2"); !errors.Is(err, ErrTaskNotFound) {
+			t.Errorf("expected ErrTaskNotFound, got: %v", err)
+		}
+		if tasks := readAllTasks(t, tmpFile); !tasks[0].IsCompleted {
+			t.Errorf("expected task to remain completed, got IsCompleted=false")
+		}
+	})
+
+	t.Run("invalid task ID returns error", func(t *testing.T) {
+		err := ReopenTask(tmpFile, "notanumber")
+		if err == nil || !strings.Contains(err.Error(), "failed to parse task ID") {
+			t.Errorf("expected error for invalid task ID, got: %v", err)
+		}
+	})
+}
+
+func TestSeveralTaskIDs(t *testing.T) {
+	const (
+		open      = "2025-05-12T10:00:00Z,false,,"
+		completed = "2025-05-12T10:00:00Z,true,,2025-05-13T10:00:00Z"
+		trashed   = "2025-05-12T10:00:00Z,false,2025-05-14T10:00:00Z,"
+	)
+	testCases := []struct {
+		name    string
+		state   string
+		mutate  func(filename string, taskIDs ...string) error
+		changed func(Task) bool
+	}{
+		{"complete", open, CompleteTask, func(task Task) bool { return task.IsCompleted }},
+		{"reopen", completed, ReopenTask, func(task Task) bool { return !task.IsCompleted }},
+		{"delete", open, DeleteTask, Task.IsDeleted},
+		{"restore", trashed, RestoreTask, func(task Task) bool { return !task.IsDeleted() }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+			var content strings.Builder
+			content.WriteString(csvHeader)
+			for id := 1; id <= 3; id++ {
+				fmt.Fprintf(&content, "%d,Task%d,%s\n", id, id, tc.state)
+			}
+			if err := os.WriteFile(tmpFile, []byte(content.String()), 0644); err != nil {
+				t.Fatalf("failed to write temp file: %v", err)
+			}
+
+			if err := tc.mutate(tmpFile, "1", "2"); err != nil {
+				t.Fatalf("%s error: %v", tc.name, err)
+			}
+			entries, err := UndoList(tmpFile)
+			if err != nil || len(entries) != 1 || len(entries[0].Changes) != 2 {
+				t.Errorf("expected one journal entry with both tasks, got %+v, %v", entries, err)
+			}
+
+			err = tc.mutate(tmpFile, "99", "3")
+			if !errors.Is(err, ErrTaskNotFound) || !strings.Contains(err.Error(), "task 99") {
+				t.Errorf("expected ErrTaskNotFound for task 99, got: %v", err)
+			}
+			for _, task := range readAllTasks(t, tmpFile) {
+				if !tc.changed(task) {
+					t.Errorf("expected task %d to be changed, got %+v", task.ID, task)
+				}
+			}
+		})
+	}
 }
@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// ListTrash returns the tasks that have been deleted but not yet purged.
+func ListTrash(filename string) ([]Task, error) {
+	return New(filename).Trash(context.Background())
+}
+
+// Trash returns the tasks that have been deleted but not yet purged.
+func (c *Client) Trash(ctx context.Context) ([]Task, error) {
+	tasks, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(tasks, func(t Task) bool { return !t.IsDeleted() }), nil
+}
+
+// RestoreTask moves tasks out of the trash. Tasks in the trash are restored even
+// when others are not; those are reported with ErrTaskNotFound.
+func RestoreTask(filename string, taskIDs ...string) error {
+	refs, err := parseTaskRefs(taskIDs)
+	if err != nil {
+		return err
+	}
+	return New(filename).restore(context.Background(), refs)
+}
+
+// Restore moves tasks out of the trash. Tasks in the trash are restored even when
+// others are not; those are reported with ErrTaskNotFound.
+func (c *Client) Restore(ctx context.Context, ids ...int) error {
+	return c.restore(ctx, idRefs(ids))
+}
+
+func (c *Client) restore(ctx context.Context, refs []taskRef) error {
+	return c.updateEach(ctx, "restore", refs, Task.IsDeleted, func(task *Task) {
+		task.DeletedAt = time.Time{}
+	})
+}
+
+// PurgeTasks permanently removes tasks that have been in the trash for longer
+// than olderThan (all of them when olderThan is zero) and returns how many were removed.
+func PurgeTasks(filename string, olderThan time.Duration) (int, error) {
+	return New(filename).Purge(context.Background(), olderThan)
+}
+
+// Purge permanently removes tasks that have been in the trash for longer than
+// olderThan (all of them when olderThan is zero) and returns how many were removed.
+func (c *Client) Purge(ctx context.Context, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	purged := 0
+	err := c.update(ctx, "purge", func(tasks []Task) ([]Task, error) {
+		kept := slices.DeleteFunc(tasks, func(t Task) bool {
+			return t.IsDeleted() && !t.DeletedAt.After(cutoff)
+		})
+		purged = len(tasks) - len(kept)
+		return kept, nil
+	})
+	return purged, err
+}
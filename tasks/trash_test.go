@@ -0,0 +1,106 @@
+package tasks
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrash(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+
+	for _, description := range []string{"Task1", "Task2", "Task3"} {
+		if err := AddTask(tmpFile, description); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+
+	t.Run("deleted task moves to trash", func(t *testing.T) {
+		if err := DeleteTask(tmpFile, "2"); err != nil {
+			t.Fatalf("DeleteTask error: %v", err)
+		}
+		visible, err := ListTasks(tmpFile, true)
+		if err != nil {
+			t.Fatalf("ListTasks error: %v", err)
+		}
+		if got := taskIDs(visible); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+			t.Errorf("expected tasks 1 and 3 to be listed, got %v", got)
+		}
+		trashed, err := ListTrash(tmpFile)
+		if err != nil {
+			t.Fatalf("ListTrash error: %v", err)
+		}
+		if len(trashed) != 1 || trashed[0].ID != 2 || !trashed[0].IsDeleted() {
+			t.Errorf("expected task 2 in trash, got %+v", trashed)
+		}
+	})
+
+	t.Run("delete of unknown or trashed task returns ErrTaskNotFound", func(t *testing.T) {
+		for _, id := range []string{"99", "2"} {
+			if err := DeleteTask(tmpFile, id); !errors.Is(err, ErrTaskNotFound) {
+				t.Errorf("DeleteTask(%s) error = %v, want ErrTaskNotFound", id, err)
+			}
+		}
+	})
+
+	t.Run("deleted task cannot be completed", func(t *testing.T) {
+		if err := CompleteTask(tmpFile, "2"); !errors.Is(err, ErrTaskNotFound) {
+			t.Errorf("CompleteTask error = %v, want ErrTaskNotFound", err)
+		}
+		task, err := GetTask(tmpFile, "2")
+		if err != nil {
+			t.Fatalf("GetTask error: %v", err)
+		}
+		if task.IsCompleted {
+			t.Errorf("expected trashed task to stay uncompleted")
+		}
+	})
+
+	t.Run("restore brings task back", func(t *testing.T) {
+		if err := RestoreTask(tmpFile, "2"); err != nil {
+			t.Fatalf("RestoreTask error: %v", err)
+		}
+		visible, err := ListTasks(tmpFile, true)
+		if err != nil {
+			t.Fatalf("ListTasks error: %v", err)
+		}
+		if len(visible) != 3 {
+			t.Errorf("expected 3 tasks after restore, got %+v", visible)
+		}
+	})
+
+	t.Run("restore of task not in trash returns ErrTaskNotFound", func(t *testing.T) {
+		if err := RestoreTask(tmpFile, "2"); !errors.Is(err, ErrTaskNotFound) {
+			t.Errorf("expected ErrTaskNotFound, got %v", err)
+		}
+	})
+
+	t.Run("purge respects older-than", func(t *testing.T) {
+		if err := DeleteTask(tmpFile, "3"); err != nil {
+			t.Fatalf("DeleteTask error: %v", err)
+		}
+		purged, err := PurgeTasks(tmpFile, time.Hour)
+		if err != nil || purged != 0 {
+			t.Fatalf("PurgeTasks(1h) = %d, %v; want 0 purged", purged, err)
+		}
+		purged, err = PurgeTasks(tmpFile, 0)
+		if err != nil || purged != 1 {
+			t.Fatalf("PurgeTasks(0) = %d, %v; want 1 purged", purged, err)
+		}
+		if got := taskIDs(readAllTasks(t, tmpFile)); len(got) != 2 {
+			t.Errorf("expected task 3 to be removed from file, got %v", got)
+		}
+	})
+}
+
+func TestReadTasksWithoutDeletedAtColumn(t *testing.T) {
+	data := []byte("ID,Description,CreatedAt,IsComplete\n1,Old,2025-05-12T10:00:00Z,false\n")
+	tasks, err := readTasksFromCSVData(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].IsDeleted() {
+		t.Errorf("expected one active task, got %+v", tasks)
+	}
+}
@@ -0,0 +1,113 @@
+package tasks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the data file must stay quiet after a change before
+// Watch reports it, so a burst of writes is reported once.
+var watchDebounce = 50 * time.Millisecond
+
+// fileState is what Watch compares to notice that a file changed. The data file
+// is replaced by rename on every rewrite, so the inode changes even when the size
+// does not and the modification time is too coarse to tell.
+type fileState struct {
+	exists  bool
+	modTime int64
+	stamp   fileStamp
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime().UnixNano(), stamp: stampOf(info)}
+}
+
+// Watch reports on the returned channel whenever the data file changes on disk
+// after Watch returns, whether through this client or another process. Changes are
+// noticed through file system notifications where available, and otherwise by
+// polling every interval. A burst of changes is reported once, as are changes made
+// before the previous one was received. The channel is closed when ctx is done.
+func (c *Client) Watch(ctx context.Context, interval time.Duration) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	last := statFile(c.filename)
+	report := func() {
+		if cur := statFile(c.filename); cur != last {
+			last = cur
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	// The data file is replaced by rename, so its directory is watched rather than
+	// the file itself.
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = watcher.Add(filepath.Dir(c.filename)); err != nil {
+			watcher.Close()
+		}
+	}
+	if err != nil {
+		go func() {
+			defer close(changes)
+			pollChanges(ctx, interval, report)
+		}()
+		return changes
+	}
+
+	go func() {
+		defer close(changes)
+		defer watcher.Close()
+		name := filepath.Clean(c.filename)
+		debounce := time.NewTimer(watchDebounce)
+		debounce.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == name {
+					debounce.Reset(watchDebounce)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// Events may have been lost, so fall back to polling from now on.
+				watcher.Close()
+				report()
+				pollChanges(ctx, interval, report)
+				return
+			case <-debounce.C:
+				report()
+			}
+		}
+	}()
+	return changes
+}
+
+// pollChanges calls report every interval until ctx is done.
+func pollChanges(ctx context.Context, interval time.Duration, report func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report()
+		}
+	}
+}
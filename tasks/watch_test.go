@@ -0,0 +1,127 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	ctx, cancel := context.WithCancel(context.Background())
+	c := New(tmpFile)
+	changes := c.Watch(ctx, 10*time.Millisecond)
+
+	// Another client stands in for another process.
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the new data file to be reported")
+	}
+
+	if err := CompleteTask(tmpFile, "1"); err != nil {
+		t.Fatalf("CompleteTask error: %v", err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the completion to be reported")
+	}
+
+	select {
+	case <-changes:
+		t.Error("expected no change to be reported while the file is unchanged")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+func TestWatchDebounce(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := New(tmpFile).Watch(ctx, time.Hour)
+
+	for range 5 {
+		if err := AddTask(tmpFile, "Burst"); err != nil {
+			t.Fatalf("AddTask error: %v", err)
+		}
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the burst to be reported")
+	}
+	select {
+	case <-changes:
+		t.Error("expected the burst to be reported once")
+	case <-time.After(4 * watchDebounce):
+	}
+}
+
+func TestWatchPollingFallback(t *testing.T) {
+	// The directory does not exist yet, so it cannot be watched for notifications.
+	tmpFile := filepath.Join(t.TempDir(), "later", "tasks.csv")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := New(tmpFile).Watch(ctx, 10*time.Millisecond)
+
+	if err := os.Mkdir(filepath.Dir(tmpFile), 0755); err != nil {
+		t.Fatalf("Mkdir error: %v", err)
+	}
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected polling to report the new data file")
+	}
+}
+
+func TestWatchSameSizeRewrite(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tasks.csv")
+	if err := AddTask(tmpFile, "Task1"); err != nil {
+		t.Fatalf("AddTask error: %v", err)
+	}
+	info, err := os.Stat(tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := New(tmpFile).Watch(ctx, 10*time.Millisecond)
+
+	// A rewrite that keeps the size and, on a coarse clock, the modification time
+	edited := filepath.Join(filepath.Dir(tmpFile), "edited.csv")
+	if err := os.WriteFile(edited, bytes.Replace(data, []byte("Task1"), []byte("Task2"), 1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(edited, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(edited, tmpFile); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the rewritten data file to be reported")
+	}
+}
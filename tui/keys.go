@@ -0,0 +1,49 @@
+package tui
+
+import "github.com/charmbracelet/bubbles/key"
+
+type keyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	NextView key.Binding
+	PrevView key.Binding
+	Add      key.Binding
+	Edit     key.Binding
+	Toggle   key.Binding
+	Reopen   key.Binding
+	Delete   key.Binding
+	Undo     key.Binding
+	Filter   key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+}
+
+var keys = keyMap{
+	Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	NextView: key.NewBinding(key.WithKeys("tab", "right", "l"), key.WithHelp("tab/→", "next view")),
+	PrevView: key.NewBinding(key.WithKeys("shift+tab", "left", "h"), key.WithHelp("shift+tab/←", "previous view")),
+	Add:      key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add")),
+	Edit:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+	Toggle:   key.NewBinding(key.WithKeys(" ", "x"), key.WithHelp("space/x", "complete/reopen")),
+	Reopen:   key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reopen/restore")),
+	Delete:   key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+	Undo:     key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo")),
+	Filter:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+	Help:     key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+	Quit:     key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+}
+
+// ShortHelp is shown below the list.
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Add, k.Toggle, k.Delete, k.Filter, k.Help, k.Quit}
+}
+
+// FullHelp is shown by the help overlay.
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.NextView, k.PrevView},
+		{k.Add, k.Edit, k.Toggle, k.Reopen, k.Delete, k.Undo},
+		{k.Filter, k.Help, k.Quit},
+	}
+}
@@ -0,0 +1,460 @@
+// Package tui is a full-screen terminal interface for triaging tasks. Every change
+// goes through a tasks.Client, so it takes the same file lock and is journaled like
+// the commands, and the screen follows changes made elsewhere while it is open.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CreepySunny/tasker/tasks"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/mergestat/timediff"
+)
+
+// pollInterval is how often the data file is checked for changes where file
+// system notifications are not available.
+const pollInterval = time.Second
+
+// view is a set of tasks shown in the sidebar.
+type view int
+
+const (
+	viewOpen view = iota
+	viewAll
+	viewCompleted
+	viewTrash
+	viewArchive
+	numViews
+)
+
+func (v view) String() string {
+	return [...]string{"Open", "All", "Completed", "Trash", "Archive"}[v]
+}
+
+// mode is what key presses currently go to.
+type mode int
+
+const (
+	modeList mode = iota
+	modeFilter
+	modeAdd
+	modeEdit
+)
+
+var (
+	sidebarStyle  = lipgloss.NewStyle().Width(14).PaddingRight(1).BorderStyle(lipgloss.NormalBorder()).BorderRight(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true).Reverse(true)
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	dimStyle      = lipgloss.NewStyle().Faint(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+type loadedMsg struct {
+	view  view
+	tasks []tasks.Task
+	err   error
+}
+
+type doneMsg struct {
+	status string
+	err    error
+}
+
+type changedMsg struct{}
+
+type model struct {
+	ctx     context.Context
+	client  *tasks.Client
+	changes <-chan struct{}
+
+	view   view
+	tasks  []tasks.Task
+	cursor int
+	offset int
+
+	mode   mode
+	filter textinput.Model
+	input  textinput.Model
+	// editing is the task as it was when editing started.
+	editing tasks.Task
+
+	status   string
+	isError  bool
+	help     help.Model
+	showHelp bool
+	width    int
+	height   int
+}
+
+// Run shows the interface on the terminal until the user quits.
+func Run(client *tasks.Client) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := newModel(ctx, client)
+	m.changes = client.Watch(ctx, pollInterval)
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+func newModel(ctx context.Context, client *tasks.Client) model {
+	newInput := func(prompt string) textinput.Model {
+		ti := textinput.New()
+		ti.Prompt = prompt
+		ti.Cursor.SetMode(cursor.CursorStatic)
+		return ti
+	}
+	return model{
+		ctx:    ctx,
+		client: client,
+		filter: newInput("/"),
+		input:  newInput("> "),
+		help:   help.New(),
+		height: 24,
+		width:  80,
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.waitForChange())
+}
+
+// load reads the tasks of the current view.
+func (m model) load() tea.Cmd {
+	v, ctx, client := m.view, m.ctx, m.client
+	return func() tea.Msg {
+		var list []tasks.Task
+		var err error
+		switch v {
+		case viewOpen, viewAll:
+			list, err = client.List(ctx, v == viewAll)
+		case viewCompleted:
+			list, err = client.List(ctx, true)
+			list = slices.DeleteFunc(list, func(t tasks.Task) bool { return !t.IsCompleted })
+		case viewTrash:
+			list, err = client.Trash(ctx)
+		case viewArchive:
+			list, err = client.Archived(ctx)
+		}
+		return loadedMsg{view: v, tasks: list, err: err}
+	}
+}
+
+// waitForChange reports the next change to the data file made by anyone.
+func (m model) waitForChange() tea.Cmd {
+	if m.changes == nil {
+		return nil
+	}
+	changes := m.changes
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+		return changedMsg{}
+	}
+}
+
+// do runs fn in the background and shows the status it returns.
+func (m model) do(fn func(ctx context.Context) (status string, err error)) tea.Cmd {
+	ctx := m.ctx
+	return func() tea.Msg {
+		status, err := fn(ctx)
+		return doneMsg{status: status, err: err}
+	}
+}
+
+// visible returns the tasks of the view that match the filter.
+func (m model) visible() []tasks.Task {
+	var visible []tasks.Task
+	for _, task := range m.tasks {
+		if task.Matches(m.filter.Value()) {
+			visible = append(visible, task)
+		}
+	}
+	return visible
+}
+
+func (m model) selected() (tasks.Task, bool) {
+	visible := m.visible()
+	if m.cursor < 0 || m.cursor >= len(visible) {
+		return tasks.Task{}, false
+	}
+	return visible[m.cursor], true
+}
+
+func (m *model) setStatus(status string, isError bool) {
+	m.status, m.isError = status, isError
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.help.Width = msg.Width
+		return m, nil
+
+	case loadedMsg:
+		if msg.view != m.view {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.setStatus(fmt.Sprintf("Failed to load tasks: %v", msg.err), true)
+			return m, nil
+		}
+		m.tasks = msg.tasks
+		m.clampCursor()
+		return m, nil
+
+	case doneMsg:
+		if msg.err != nil {
+			m.setStatus(msg.err.Error(), true)
+		} else {
+			m.setStatus(msg.status, false)
+		}
+		return m, m.load()
+
+	case changedMsg:
+		return m, tea.Batch(m.load(), m.waitForChange())
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case modeFilter:
+			return m.updateFilter(msg)
+		case modeAdd, modeEdit:
+			return m.updateInput(msg)
+		}
+		return m.updateList(msg)
+	}
+	return m, nil
+}
+
+func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.showHelp {
+		m.showHelp = false
+		if key.Matches(msg, keys.Quit) {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	task, ok := m.selected()
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Help):
+		m.showHelp = true
+	case key.Matches(msg, keys.Up):
+		m.cursor--
+		m.clampCursor()
+	case key.Matches(msg, keys.Down):
+		m.cursor++
+		m.clampCursor()
+	case key.Matches(msg, keys.NextView), key.Matches(msg, keys.PrevView):
+		step := view(1)
+		if key.Matches(msg, keys.PrevView) {
+			step = numViews - 1
+		}
+		m.view = (m.view + step) % numViews
+		m.tasks, m.cursor, m.offset = nil, 0, 0
+		return m, m.load()
+	case key.Matches(msg, keys.Filter):
+		m.mode = modeFilter
+		return m, m.filter.Focus()
+	case key.Matches(msg, keys.Add):
+		m.mode = modeAdd
+		m.input.SetValue("")
+		m.input.Placeholder = "New task"
+		return m, m.input.Focus()
+	case key.Matches(msg, keys.Undo):
+		return m, m.do(func(ctx context.Context) (string, error) {
+			entry, err := m.client.Undo(ctx)
+			return "Undid " + entry.Op, err
+		})
+	}
+	if !ok || !m.isChangeKey(msg) {
+		return m, nil
+	}
+
+	if m.view == viewArchive {
+		m.setStatus("Archived tasks cannot be changed", true)
+		return m, nil
+	}
+	yes, no := true, false
+	switch {
+	case key.Matches(msg, keys.Edit) && m.view != viewTrash:
+		m.mode, m.editing = modeEdit, task
+		m.input.SetValue(task.Description)
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	case key.Matches(msg, keys.Toggle) && m.view != viewTrash:
+		status := fmt.Sprintf("Completed #%d", task.ID)
+		if task.IsCompleted {
+			status = fmt.Sprintf("Reopened #%d", task.ID)
+		}
+		completed := !task.IsCompleted
+		return m, m.edit(status, task, tasks.TaskPatch{IsCompleted: &completed})
+	case key.Matches(msg, keys.Reopen) && m.view == viewTrash:
+		return m, m.edit(fmt.Sprintf("Restored #%d", task.ID), task, tasks.TaskPatch{IsDeleted: &no})
+	case key.Matches(msg, keys.Reopen) && task.IsCompleted:
+		return m, m.edit(fmt.Sprintf("Reopened #%d", task.ID), task, tasks.TaskPatch{IsCompleted: &no})
+	case key.Matches(msg, keys.Delete) && m.view != viewTrash:
+		return m, m.edit(fmt.Sprintf("Moved #%d to the trash (u to undo)", task.ID), task, tasks.TaskPatch{IsDeleted: &yes})
+	}
+	return m, nil
+}
+
+func (m model) isChangeKey(msg tea.KeyMsg) bool {
+	return key.Matches(msg, keys.Edit, keys.Toggle, keys.Reopen, keys.Delete)
+}
+
+// edit applies patch to task, unless it changed since it was loaded.
+func (m model) edit(status string, task tasks.Task, patch tasks.TaskPatch) tea.Cmd {
+	return m.do(func(ctx context.Context) (string, error) {
+		_, err := m.client.Edit(ctx, task.ID, patch, task.Version())
+		return status, err
+	})
+}
+
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.filter.SetValue("")
+		fallthrough
+	case tea.KeyEnter:
+		m.mode = modeList
+		m.filter.Blur()
+		m.clampCursor()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.cursor, m.offset = 0, 0
+	return m, cmd
+}
+
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mode = modeList
+		m.input.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		description := strings.TrimSpace(m.input.Value())
+		adding := m.mode == modeAdd
+		m.mode = modeList
+		m.input.Blur()
+		if description == "" {
+			return m, nil
+		}
+		if adding {
+			return m, m.do(func(ctx context.Context) (string, error) {
+				task, err := m.client.Add(ctx, description)
+				return fmt.Sprintf("Added #%d", task.ID), err
+			})
+		}
+		task := m.editing
+		return m, m.edit(fmt.Sprintf("Edited #%d", task.ID), task, tasks.TaskPatch{Description: &description})
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// listHeight is how many tasks fit on the screen.
+func (m model) listHeight() int {
+	// Title, status, input or filter line and help
+	return max(m.height-4, 1)
+}
+
+// clampCursor keeps the cursor on a task and scrolls it into view.
+func (m *model) clampCursor() {
+	n := len(m.visible())
+	m.cursor = max(min(m.cursor, n-1), 0)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.listHeight() {
+		m.offset = m.cursor - m.listHeight() + 1
+	}
+}
+
+func (m model) View() string {
+	var sidebar strings.Builder
+	for v := range numViews {
+		name := v.String()
+		if v == m.view {
+			name = selectedStyle.Render(name)
+		}
+		fmt.Fprintln(&sidebar, name)
+	}
+
+	var main strings.Builder
+	title := fmt.Sprintf("%s (%d)", m.view, len(m.visible()))
+	if m.filter.Value() != "" {
+		title += dimStyle.Render(" matching " + m.filter.Value())
+	}
+	fmt.Fprintln(&main, titleStyle.Render(title))
+	if m.showHelp {
+		fmt.Fprint(&main, m.help.FullHelpView(keys.FullHelp()))
+	} else {
+		m.renderTasks(&main)
+	}
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		sidebarStyle.Height(m.listHeight()+1).Render(sidebar.String()),
+		" "+main.String())
+
+	var footer strings.Builder
+	switch m.mode {
+	case modeFilter:
+		fmt.Fprintln(&footer, m.filter.View())
+	case modeAdd, modeEdit:
+		fmt.Fprintln(&footer, m.input.View())
+	default:
+		if m.isError {
+			fmt.Fprintln(&footer, errorStyle.Render(m.status))
+		} else {
+			fmt.Fprintln(&footer, m.status)
+		}
+	}
+	fmt.Fprint(&footer, m.help.ShortHelpView(keys.ShortHelp()))
+	return lipgloss.JoinVertical(lipgloss.Left, body, footer.String())
+}
+
+func (m model) renderTasks(b *strings.Builder) {
+	visible := m.visible()
+	if len(visible) == 0 {
+		fmt.Fprintln(b, dimStyle.Render("No tasks."))
+		return
+	}
+	width := max(m.width-sidebarStyle.GetWidth()-4, 20)
+	end := min(m.offset+m.listHeight(), len(visible))
+	for i, task := range visible[m.offset:end] {
+		check := "[ ]"
+		if task.IsCompleted {
+			check = "[x]"
+		}
+		when := timediff.TimeDiff(task.CreatedAt)
+		if task.IsDeleted() {
+			when = "deleted " + timediff.TimeDiff(task.DeletedAt)
+		}
+		prefix := fmt.Sprintf("%s %3d ", check, task.ID)
+		room := max(width-len(prefix)-len(when)-1, 1)
+		// Measured in terminal cells, as wide characters such as CJK take two
+		description := runewidth.FillRight(runewidth.Truncate(task.Description, room, "…"), room)
+		line := fmt.Sprintf("%s%s %s", prefix, description, dimStyle.Render(when))
+		if m.offset+i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		fmt.Fprintln(b, line)
+	}
+}
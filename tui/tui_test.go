@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CreepySunny/tasker/tasks"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// settle feeds m the messages of cmd and the commands they lead to, until none are left.
+func settle(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	queue := []tea.Cmd{cmd}
+	for len(queue) > 0 {
+		cmd, queue = queue[0], queue[1:]
+		if cmd == nil {
+			continue
+		}
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			queue = append(queue, batch...)
+			continue
+		}
+		next, cmd := m.Update(msg)
+		m = next.(model)
+		queue = append(queue, cmd)
+	}
+	return m
+}
+
+// press sends keys to m one at a time, as typed.
+func press(t *testing.T, m model, keys ...string) model {
+	t.Helper()
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		next, cmd := m.Update(msg)
+		m = settle(t, next.(model), cmd)
+	}
+	return m
+}
+
+func typed(s string) []string {
+	return strings.Split(s, "")
+}
+
+func newTestModel(t *testing.T, descriptions ...string) (model, *tasks.Client) {
+	t.Helper()
+	client := tasks.New(filepath.Join(t.TempDir(), "tasks.csv"))
+	for _, desc := range descriptions {
+		if _, err := client.Add(context.Background(), desc); err != nil {
+			t.Fatalf("Add error: %v", err)
+		}
+	}
+	m := newModel(context.Background(), client)
+	return settle(t, m, m.load()), client
+}
+
+func TestAddAndEdit(t *testing.T) {
+	m, client := newTestModel(t)
+
+	m = press(t, m, "a")
+	m = press(t, m, typed("Tidy my desk")...)
+	m = press(t, m, "enter")
+	if len(m.tasks) != 1 || m.tasks[0].Description != "Tidy my desk" || m.status != "Added #1" {
+		t.Fatalf("after add: tasks %+v, status %q", m.tasks, m.status)
+	}
+
+	m = press(t, m, "e")
+	m = press(t, m, typed(" and shelves")...)
+	m = press(t, m, "enter")
+	task, err := client.Get(context.Background(), 1)
+	if err != nil || task.Description != "Tidy my desk and shelves" {
+		t.Errorf("after edit: %+v, %v", task, err)
+	}
+
+	m = press(t, m, "a")
+	m = press(t, m, typed("Discarded")...)
+	m = press(t, m, "esc")
+	if len(m.tasks) != 1 {
+		t.Errorf("expected esc to cancel adding, got %+v", m.tasks)
+	}
+}
+
+func TestCompleteDeleteAndUndo(t *testing.T) {
+	m, client := newTestModel(t, "Task1", "Task2")
+
+	m = press(t, m, "j", " ")
+	if len(m.tasks) != 1 || m.tasks[0].ID != 1 {
+		t.Fatalf("expected task 2 to leave the open view, got %+v", m.tasks)
+	}
+	m = press(t, m, "d")
+	if len(m.tasks) != 0 {
+		t.Fatalf("expected task 1 to move to the trash, got %+v", m.tasks)
+	}
+	m = press(t, m, "u")
+	if len(m.tasks) != 1 || m.status != "Undid delete" {
+		t.Errorf("after undo: tasks %+v, status %q", m.tasks, m.status)
+	}
+
+	// Completed, then Trash
+	m = press(t, m, "tab", "tab")
+	if m.view != viewCompleted || len(m.tasks) != 1 || m.tasks[0].ID != 2 {
+		t.Fatalf("completed view: %v %+v", m.view, m.tasks)
+	}
+	m = press(t, m, "r")
+	if got, _ := client.Get(context.Background(), 2); got.IsCompleted {
+		t.Errorf("expected task 2 to be reopened")
+	}
+}
+
+func TestTrashView(t *testing.T) {
+	m, client := newTestModel(t, "Task1")
+	if err := client.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("Delete error: %v", err)
+	}
+	m = press(t, m, "tab", "tab", "tab")
+	if m.view != viewTrash || len(m.tasks) != 1 {
+		t.Fatalf("trash view: %v %+v", m.view, m.tasks)
+	}
+	if !strings.Contains(m.View(), "deleted") {
+		t.Errorf("expected the trash to show when tasks were deleted:\n%s", m.View())
+	}
+	m = press(t, m, "r")
+	if len(m.tasks) != 0 || m.status != "Restored #1" {
+		t.Errorf("after restore: tasks %+v, status %q", m.tasks, m.status)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	m, _ := newTestModel(t, "Buy groceries", "Water the plants", "Buy plant food")
+	m = press(t, m, "/")
+	m = press(t, m, typed("buy")...)
+	if got := len(m.visible()); got != 2 {
+		t.Errorf("expected 2 tasks matching the filter, got %d", got)
+	}
+	m = press(t, m, "enter")
+	if m.mode != modeList || len(m.visible()) != 2 {
+		t.Errorf("expected enter to keep the filter, got mode %v, %d tasks", m.mode, len(m.visible()))
+	}
+	view := m.View()
+	if !strings.Contains(view, "Buy plant food") || strings.Contains(view, "Water the plants") {
+		t.Errorf("filtered view:\n%s", view)
+	}
+	m = press(t, m, "/", "esc")
+	if len(m.visible()) != 3 {
+		t.Errorf("expected esc to clear the filter, got %d tasks", len(m.visible()))
+	}
+}
+
+func TestStaleTaskConflicts(t *testing.T) {
+	m, client := newTestModel(t, "Task1")
+	// Another process changes the task after the view was loaded.
+	desc := "Changed elsewhere"
+	if _, err := client.Edit(context.Background(), 1, tasks.TaskPatch{Description: &desc}, ""); err != nil {
+		t.Fatalf("Edit error: %v", err)
+	}
+	m = press(t, m, "d")
+	if !m.isError || !strings.Contains(m.status, "changed") {
+		t.Errorf("expected a conflict, got status %q", m.status)
+	}
+	if len(m.tasks) != 1 || m.tasks[0].Description != desc {
+		t.Errorf("expected the view to show the latest version, got %+v", m.tasks)
+	}
+}
+
+func TestWideCharacters(t *testing.T) {
+	m, _ := newTestModel(t, "整理书桌和书架上的所有东西然后去超市买牛奶和面包", "Buy milk 🥛")
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+	m = next.(model)
+
+	var b strings.Builder
+	m.renderTasks(&b)
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 rows, got %q", lines)
+	}
+	// Rows line up when they take the same number of cells
+	first, second := lipgloss.Width(lines[0]), lipgloss.Width(lines[1])
+	if first != second || first > 60 {
+		t.Errorf("rows are %d and %d cells wide, want equal and at most 60:\n%s", first, second, b.String())
+	}
+	if !strings.Contains(lines[0], "…") {
+		t.Errorf("expected the long description to be truncated, got %q", lines[0])
+	}
+}
+
+func TestHelpAndQuit(t *testing.T) {
+	m, _ := newTestModel(t)
+	m = press(t, m, "?")
+	if !m.showHelp || !strings.Contains(m.View(), "reopen/restore") {
+		t.Errorf("expected the help overlay:\n%s", m.View())
+	}
+	m = press(t, m, "j")
+	if m.showHelp {
+		t.Errorf("expected any key to close the help overlay")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil {
+		t.Errorf("expected q to quit")
+	}
+}